@@ -0,0 +1,275 @@
+package gerrors
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeyCase is the case of the metadata keys of the errors. Check [WithKeyCase].
+type KeyCase int
+
+const (
+	// KeyCaseOriginal keeps the metadata keys as they are given.
+	KeyCaseOriginal KeyCase = iota
+
+	// KeyCaseLowerCamel converts snake-case and kebab-case metadata keys to lowerCamelCase,
+	// as recommended by AIP-193. e.g. "user_id" becomes "userId" and "_error_code" becomes "errorCode".
+	KeyCaseLowerCamel
+)
+
+const (
+	// aipReasonMaxLength is the upper limit for the length of AIP-193 reasons.
+	aipReasonMaxLength = 63
+
+	// aipKeyMaxLength is the upper limit for the length of AIP-193 metadata keys.
+	aipKeyMaxLength = 64
+)
+
+var (
+	// ErrAIP193Violation is reported whenever a formatter or a catalog does not comply with AIP-193.
+	ErrAIP193Violation = errors.New("AIP-193 violation")
+
+	// aipReasonRE is the format of AIP-193 reasons. e.g. "USER_NOT_FOUND"
+	aipReasonRE = regexp.MustCompile(`^[A-Z][A-Z0-9_]+[A-Z0-9]$`)
+
+	// aipKeyRE is the format of AIP-193 metadata keys. e.g. "userId"
+	aipKeyRE = regexp.MustCompile(`^[a-z][a-zA-Z0-9-_]+$`)
+
+	// aipReasonSeparatorRE matches the characters that are replaced by underscores in derived reasons.
+	aipReasonSeparatorRE = regexp.MustCompile(`[^A-Z0-9]+`)
+)
+
+// WithKeyCase sets the case of the metadata keys of the errors, including the system keys,
+// e.g. [MetadataErrorCode]. Keys are converted when the error details are generated, so
+// message templates still refer to labels by the keys they are given with.
+// It defaults to [KeyCaseOriginal].
+func WithKeyCase(keyCase KeyCase) FormatterOption {
+	return func(f *Formatter) {
+		f.keyCase = keyCase
+	}
+}
+
+// WithAIP193 turns on the strict AIP-193 mode of the formatter:
+//   - reasons are derived from identifiers by replacing every run of characters other than
+//     letters and digits with an underscore, e.g. "not-found" becomes "NOT_FOUND",
+//     unless the [CoreError] implements [CoreReasonError],
+//   - metadata keys are converted to lowerCamelCase. Check [WithKeyCase].
+//
+// The formatter is validated when it is built. Check [Formatter.ValidateAIP193] for the rules.
+func WithAIP193() FormatterOption {
+	return func(f *Formatter) {
+		f.aip193 = true
+		f.keyCase = KeyCaseLowerCamel
+	}
+}
+
+// ValidateAIP193 checks the formatter against AIP-193 and reports every violation, joined together.
+// Every code of the formatter's lookuper needs a reason that matches [A-Z][A-Z0-9_]+[A-Z0-9]
+// and a domain, and the formatter's labels need lowerCamelCase keys.
+// Codes are only checked if the lookuper implements [EnumerableLookuper].
+func (f *Formatter) ValidateAIP193() error {
+	var errs []error
+
+	if l, ok := f.coreDataLookup.(EnumerableLookuper); ok {
+		for _, core := range l.All() {
+			errs = append(errs, validateAIP193Core(core, f.reasonOf(core), f.domainOf(core))...)
+		}
+	}
+
+	for _, key := range f.outputSystemKeys() {
+		if !isAIP193Key(key) {
+			errs = append(errs, fmt.Errorf("%w: system key %q is not lowerCamelCase", ErrAIP193Violation, key))
+		}
+	}
+
+	for key := range f.labels {
+		if converted := f.keyCase.convert(key); !isAIP193Key(converted) {
+			errs = append(errs, fmt.Errorf("%w: label key %q is not lowerCamelCase", ErrAIP193Violation, converted))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateAIP193 checks the catalog against AIP-193 and reports every violation, joined together.
+// Every entry needs a reason that matches [A-Z][A-Z0-9_]+[A-Z0-9], either explicit or derived from
+// its identifier as explained in [WithAIP193], and a domain of its own or of the catalog.
+// The catalog should be validated beforehand.
+func (c *Catalog) ValidateAIP193() error {
+	var errs []error
+
+	for _, entry := range c.Entries {
+		if entry == nil {
+			continue
+		}
+
+		errs = append(errs, validateAIP193Core(entry, aipReasonOf(entry), c.domainOf(entry))...)
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateAIP193Core(core CoreError, reason, domain string) []error {
+	var errs []error
+
+	if len(reason) > aipReasonMaxLength || !aipReasonRE.MatchString(reason) {
+		errs = append(errs, fmt.Errorf("%w: code %d has invalid reason %q",
+			ErrAIP193Violation, core.GetInternalCode(), reason))
+	}
+
+	if domain == "" {
+		errs = append(errs, fmt.Errorf("%w: code %d has no domain", ErrAIP193Violation, core.GetInternalCode()))
+	}
+
+	if schema := labelSchemaOf(core); schema != nil {
+		for _, spec := range schema.Labels {
+			if key := KeyCaseLowerCamel.convert(spec.Key); !isAIP193Key(key) {
+				errs = append(errs, fmt.Errorf("%w: code %d declares label %q that is not lowerCamelCase",
+					ErrAIP193Violation, core.GetInternalCode(), key))
+			}
+		}
+	}
+
+	return errs
+}
+
+// reasonOf returns the reason of the error details of the core error, based on the formatter's mode.
+func (f *Formatter) reasonOf(core CoreError) string {
+	if f.aip193 {
+		return aipReasonOf(core)
+	}
+
+	return reasonOf(core)
+}
+
+// aipReasonOf returns the AIP-193 reason of the core error. Check [WithAIP193].
+func aipReasonOf(core CoreError) string {
+	if corer, ok := core.(CoreReasonError); ok && corer.GetReason() != "" {
+		return corer.GetReason()
+	}
+
+	return strings.Trim(aipReasonSeparatorRE.ReplaceAllString(strings.ToUpper(core.GetIdentifier()), "_"), "_")
+}
+
+// reasonsOf returns every reason the core error can be sent with, which are used for reverse lookups.
+func reasonsOf(core CoreError) []string {
+	reason, aipReason := reasonOf(core), aipReasonOf(core)
+	if reason == aipReason {
+		return []string{reason}
+	}
+
+	return []string{reason, aipReason}
+}
+
+func isAIP193Key(key string) bool {
+	return len(key) <= aipKeyMaxLength && aipKeyRE.MatchString(key)
+}
+
+// convert converts the key to the case. Only "_" and "-" separate words, and the case
+// of the letters is kept, except for the leading capitals of the first word, so keys that
+// are already camelCase are kept as they are, e.g. "userId", and "HTTPStatus" becomes "httpStatus".
+func (c KeyCase) convert(key string) string {
+	if c != KeyCaseLowerCamel {
+		return key
+	}
+
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return r == '_' || r == '-'
+	})
+
+	var sb strings.Builder
+
+	for i, word := range words {
+		if i == 0 {
+			sb.WriteString(lowerLeadingCapitals(word))
+
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(word)
+		sb.WriteRune(unicode.ToUpper(r))
+		sb.WriteString(word[size:])
+	}
+
+	return sb.String()
+}
+
+// lowerLeadingCapitals lower-cases the leading capitals of the word. The last one is kept
+// if it starts the next part of the word, e.g. "HTTPStatus" becomes "httpStatus".
+func lowerLeadingCapitals(word string) string {
+	runes := []rune(word)
+
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+
+	if n > 1 && n < len(runes) && unicode.IsLower(runes[n]) {
+		n--
+	}
+
+	for i := range n {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+
+	return string(runes)
+}
+
+// convertKeys returns the metadata with its keys converted to the formatter's key case.
+// Keys that collide after the conversion, e.g. "user_id" and "user-id", are all kept:
+// system keys come first, then the keys that are already in the case, then the rest in
+// order, and a numeric suffix is added to the keys that come later, e.g. "userId2".
+func (f *Formatter) convertKeys(metadata map[string]string) map[string]string {
+	c := f.keyCase
+	if c != KeyCaseLowerCamel {
+		return metadata
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+
+	rank := func(key string) int {
+		switch {
+		case f.isReservedKey(key):
+			return 0
+		case c.convert(key) == key:
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if ri, rj := rank(keys[i]), rank(keys[j]); ri != rj {
+			return ri < rj
+		}
+
+		return keys[i] < keys[j]
+	})
+
+	converted := make(map[string]string, len(metadata))
+
+	for _, k := range keys {
+		key := c.convert(k)
+
+		for n := 2; ; n++ {
+			if _, ok := converted[key]; !ok {
+				break
+			}
+
+			key = c.convert(k + "_" + strconv.Itoa(n))
+		}
+
+		converted[key] = metadata[k]
+	}
+
+	return converted
+}
@@ -0,0 +1,240 @@
+package gerrors_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithAIP193(t *testing.T) {
+	t.Parallel()
+
+	f := gerrors.NewFormatter(gerrors.WithAIP193(), gerrors.WithDomain("billing.example.com"))
+	err := f.New(nil, gerrors.NotFound, "user_id", "john", "invoice-number", 42)
+
+	st, _ := status.FromError(err.Grpc())
+
+	var info *errdetails.ErrorInfo
+
+	for _, detail := range st.Details() {
+		if i, ok := detail.(*errdetails.ErrorInfo); ok {
+			info = i
+		}
+	}
+
+	if info == nil {
+		t.Fatalf("expected error info detail")
+	}
+
+	if info.GetReason() != "NOT_FOUND" {
+		t.Errorf("expected reason NOT_FOUND, got %s", info.GetReason())
+	}
+
+	for _, key := range []string{"userId", "invoiceNumber", "errorCode", "identifier", "defaultMessage", "domain"} {
+		if _, ok := info.GetMetadata()[key]; !ok {
+			t.Errorf("expected metadata key %s, got %v", key, info.GetMetadata())
+		}
+	}
+
+	for key := range info.GetMetadata() {
+		if strings.ContainsAny(key, "_-") {
+			t.Errorf("expected lowerCamelCase metadata key, got %s", key)
+		}
+	}
+
+	out, _ := err.RenderWith(gerrors.JSONRenderer{})
+	if !strings.Contains(out, `"labels":{"invoiceNumber":"42","userId":"john"}`) {
+		t.Errorf("expected only user labels in rendered error, got %s", out)
+	}
+
+	if !gerrors.IsCode(err.Grpc(), "billing.example.com", gerrors.NotFound) {
+		t.Errorf("expected strict gRPC error to match its code")
+	}
+
+	if !gerrors.IsReason(err, "billing.example.com", "NOT_FOUND") {
+		t.Errorf("expected strict error to match its reason")
+	}
+
+	if core, ok := f.LookupReason("billing.example.com", "NOT_FOUND"); !ok || core.GetInternalCode() != gerrors.NotFound {
+		t.Errorf("expected strict reason lookup, got %v", core)
+	}
+}
+
+func TestWithKeyCase(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		keyCase     gerrors.KeyCase
+		key         string
+		expectedKey string
+	}{
+		{name: "original", keyCase: gerrors.KeyCaseOriginal, key: "user_id", expectedKey: "user_id"},
+		{name: "snake case", keyCase: gerrors.KeyCaseLowerCamel, key: "user_id", expectedKey: "userId"},
+		{name: "kebab case", keyCase: gerrors.KeyCaseLowerCamel, key: "user-id", expectedKey: "userId"},
+		{name: "camel case", keyCase: gerrors.KeyCaseLowerCamel, key: "userID", expectedKey: "userID"},
+		{name: "lower camel case", keyCase: gerrors.KeyCaseLowerCamel, key: "userId", expectedKey: "userId"},
+		{name: "leading initialism", keyCase: gerrors.KeyCaseLowerCamel, key: "HTTPStatus", expectedKey: "httpStatus"},
+		{name: "upper snake case", keyCase: gerrors.KeyCaseLowerCamel, key: "USER_ID", expectedKey: "userID"},
+		{name: "system key", keyCase: gerrors.KeyCaseLowerCamel, key: "_error_code", expectedKey: "errorCode"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := gerrors.NewFormatter(gerrors.WithKeyCase(tc.keyCase))
+			err := f.New(nil, gerrors.NotFound, tc.key, "value")
+
+			if _, ok := err.Metadata()[tc.expectedKey]; !ok {
+				t.Errorf("expected key %s, got %v", tc.expectedKey, err.Metadata())
+			}
+		})
+	}
+}
+
+func TestWithKeyCaseCollisions(t *testing.T) {
+	t.Parallel()
+
+	f := gerrors.NewFormatter(gerrors.WithKeyCase(gerrors.KeyCaseLowerCamel))
+	err := f.New(nil, gerrors.NotFound, "user_id", "a", "user-id", "b", "userId", "c", "errorCode", "d")
+
+	expected := map[string]string{
+		"userId":     "c",
+		"userId2":    "b",
+		"userId3":    "a",
+		"errorCode":  "2",
+		"errorCode2": "d",
+	}
+
+	for k, v := range expected {
+		if err.Metadata()[k] != v {
+			t.Errorf("expected %s=%s, got %v", k, v, err.Metadata())
+		}
+	}
+}
+
+func TestWithKeyCaseMessageTemplate(t *testing.T) {
+	t.Parallel()
+
+	mapper, err := gerrors.LoadCatalog(strings.NewReader(testCatalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := gerrors.NewFormatter(gerrors.WithLookuper(mapper), gerrors.WithKeyCase(gerrors.KeyCaseLowerCamel))
+
+	if msg := f.New(nil, 100, "user_id", "john").DefaultMessage(); msg != "user john was not found" {
+		t.Errorf("expected message template to use original keys, got %s", msg)
+	}
+}
+
+func TestNewFormatterEAIP193(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		opts          []gerrors.FormatterOption
+		expectedError []string
+	}{
+		{
+			name:          "compliant",
+			opts:          []gerrors.FormatterOption{gerrors.WithAIP193(), gerrors.WithDomain("example.com")},
+			expectedError: nil,
+		},
+		{
+			name:          "not strict",
+			opts:          []gerrors.FormatterOption{gerrors.WithLabels("1st", "x")},
+			expectedError: nil,
+		},
+		{
+			name:          "no domain",
+			opts:          []gerrors.FormatterOption{gerrors.WithAIP193()},
+			expectedError: []string{"code 1 has no domain", "code 2 has no domain"},
+		},
+		{
+			name: "invalid label key",
+			opts: []gerrors.FormatterOption{
+				gerrors.WithAIP193(), gerrors.WithDomain("example.com"), gerrors.WithLabels("1st", "x"),
+			},
+			expectedError: []string{`label key "1st" is not lowerCamelCase`},
+		},
+		{
+			name: "invalid reason",
+			opts: []gerrors.FormatterOption{
+				gerrors.WithAIP193(),
+				gerrors.WithDomain("example.com"),
+				gerrors.WithLookuper(gerrors.NewMapper(1, map[gerrors.Code]gerrors.CoreError{
+					1: composeCore{code: 1, identifier: "x"},
+				})),
+			},
+			expectedError: []string{`code 1 has invalid reason "X"`},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := gerrors.NewFormatterE(tc.opts...)
+
+			if len(tc.expectedError) == 0 {
+				if err != nil || f == nil {
+					t.Errorf("expected formatter, got error %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, gerrors.ErrAIP193Violation) {
+				t.Fatalf("expected AIP-193 violation, got %v", err)
+			}
+
+			for _, expected := range tc.expectedError {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error to contain %q, got %v", expected, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCatalogValidateAIP193(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := gerrors.ParseCatalog(strings.NewReader(`{
+  "unknown_code": 1,
+  "domain": "example.com",
+  "errors": [
+    {"code": 1, "identifier": "unknown", "message": "unknown error"},
+    {"code": 2, "identifier": "user-not-found", "message": "user was not found"},
+    {"code": 3, "identifier": "quota", "message": "quota exceeded", "reason": "quota-exceeded"},
+    {"code": 4, "identifier": "rate limited", "message": "slow down", "reason": "RATE_LIMITED"}
+  ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = catalog.ValidateAIP193()
+	if !errors.Is(err, gerrors.ErrAIP193Violation) || !strings.Contains(err.Error(), `code 3 has invalid reason "quota-exceeded"`) {
+		t.Errorf("expected invalid reason violation, got %v", err)
+	}
+
+	if strings.Contains(err.Error(), "code 2") || strings.Contains(err.Error(), "code 4") {
+		t.Errorf("expected derived and explicit reasons to be valid, got %v", err)
+	}
+
+	core, ok := catalog.Mapper().ByReason("RATE_LIMITED")
+	if !ok || core.GetInternalCode() != 4 {
+		t.Errorf("expected lookup by explicit reason, got %v", core)
+	}
+}
@@ -0,0 +1,344 @@
+package gerrors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// TruncationStrategy decides what happens to label values that exceed the per-value
+// limit of a [MetadataBudget].
+type TruncationStrategy int
+
+const (
+	// TruncateEllipsis cuts the value at the limit, ending it with an ellipsis.
+	TruncateEllipsis TruncationStrategy = iota
+
+	// TruncateHash replaces the value with a short SHA-256 hash of it, e.g. "sha256:9f86d081884c7d65",
+	// so that it can still be correlated with the full value in the logs.
+	TruncateHash
+
+	// TruncateDrop drops the label.
+	TruncateDrop
+)
+
+const (
+	// truncationEllipsis ends the values that are cut by [TruncateEllipsis].
+	truncationEllipsis = "…"
+
+	// truncationHashPrefix starts the values that are replaced by [TruncateHash].
+	truncationHashPrefix = "sha256:"
+
+	// truncationHashLength is the number of hex characters of the hash kept by [TruncateHash].
+	truncationHashLength = 16
+)
+
+// MetadataBudget limits the size of the metadata of errors that leave the process,
+// e.g. gRPC status details travel in trailers, which are usually limited to 8 KB.
+// Zero limits are not enforced. Check [WithMetadataBudget] for more information.
+type MetadataBudget struct {
+	// MaxValueBytes is the maximum length of a single label value, in bytes.
+	MaxValueBytes int
+
+	// MaxKeys is the maximum number of labels, including the system labels.
+	MaxKeys int
+
+	// MaxTotalBytes is the maximum length of all the label keys and values together, in bytes.
+	MaxTotalBytes int
+
+	// MaxMessageBytes is the maximum length, in bytes, of the gRPC status message and of the
+	// messages of the LocalizedMessage and Help details, which travel along with the metadata.
+	// Longer messages are cut, ending with an ellipsis. It defaults to MaxTotalBytes.
+	MaxMessageBytes int
+
+	// Strategy decides what happens to values that exceed MaxValueBytes.
+	Strategy TruncationStrategy
+
+	// Priority lists label keys that should be kept the longest, most important first.
+	// Whenever MaxKeys or MaxTotalBytes is exceeded, labels are dropped by priority, least
+	// important first: labels that are not listed, sorted by key, then the original error,
+	// then the listed labels. The identifier, error code, domain, requested code, default
+	// message and operation system labels are dropped last, in reverse order.
+	Priority []string
+}
+
+// WithMetadataBudget limits the size of the metadata and the messages that are sent by
+// [GrpcError], and of the metadata rendered by renderers, e.g. [JSONRenderer]. Whenever
+// a label is truncated or dropped, the [MetadataTruncated] label lists the affected keys,
+// within the same limits. Loggers, [GeneralError.Metadata] and [GeneralError.MetadataSlice]
+// still have the full values.
+//
+//	f := NewFormatter(WithMetadataBudget(MetadataBudget{
+//		MaxValueBytes: 1024,
+//		MaxTotalBytes: 6 * 1024,
+//		Strategy:      TruncateEllipsis,
+//		Priority:      []string{"user_id"},
+//	}))
+func WithMetadataBudget(budget MetadataBudget) FormatterOption {
+	return func(f *Formatter) {
+		budget.Priority = slices.Clone(budget.Priority)
+		f.budget = &budget
+	}
+}
+
+// transportDetails returns the error details that are sent to other processes, within the budget.
+func (ge *GeneralError) transportDetails() *errdetails.ErrorInfo {
+	details := ge.getDetails()
+	if ge.formatter.budget == nil {
+		return details
+	}
+
+	return &errdetails.ErrorInfo{
+		Reason:   details.GetReason(),
+		Domain:   details.GetDomain(),
+		Metadata: ge.formatter.budgetMetadata(details.GetMetadata()),
+	}
+}
+
+// budgetMessage returns the message within the formatter's budget. Check [MetadataBudget.MaxMessageBytes].
+func (f *Formatter) budgetMessage(message string) string {
+	if f.budget == nil {
+		return message
+	}
+
+	limit := f.budget.MaxMessageBytes
+	if limit <= 0 {
+		limit = f.budget.MaxTotalBytes
+	}
+
+	if limit <= 0 || len(message) <= limit {
+		return message
+	}
+
+	return truncateValue(message, limit)
+}
+
+// budgetMetadata returns the metadata within the formatter's budget. The given metadata is not modified.
+func (f *Formatter) budgetMetadata(metadata map[string]string) map[string]string {
+	budget := f.budget
+	if budget == nil {
+		return metadata
+	}
+
+	result := make(map[string]string, len(metadata))
+	affected := make(map[string]bool)
+
+	for k, v := range metadata {
+		if budget.MaxValueBytes <= 0 || len(v) <= budget.MaxValueBytes {
+			result[k] = v
+
+			continue
+		}
+
+		affected[k] = true
+
+		switch budget.Strategy {
+		case TruncateEllipsis:
+			result[k] = truncateValue(v, budget.MaxValueBytes)
+		case TruncateHash:
+			sum := sha256.Sum256([]byte(v))
+			result[k] = cutValue(truncationHashPrefix+hex.EncodeToString(sum[:])[:truncationHashLength], budget.MaxValueBytes)
+		case TruncateDrop:
+		}
+	}
+
+	order := f.budgetOrder(result)
+
+	// Labels are dropped, least important first, until the metadata fits.
+	for len(order) > 0 && !budget.fits(len(result), metadataSize(result)) {
+		drop := order[len(order)-1]
+		order = order[:len(order)-1]
+
+		delete(result, drop)
+		affected[drop] = true
+	}
+
+	markerKey, marker := f.systemKey(MetadataTruncated)
+	if !marker || len(affected) == 0 {
+		return result
+	}
+
+	markerKey = f.keyCase.convert(markerKey)
+	essential := f.essentialKeys()
+
+	// The marker has to fit the budget as well. More labels are dropped to make room for it,
+	// but the identifier and the error code are never dropped for it; the marker is left out instead.
+	for {
+		if value, ok := budget.marker(affected, len(result)+1, metadataSize(result)+len(markerKey)); ok {
+			result[markerKey] = value
+
+			break
+		}
+
+		if len(order) == 0 || slices.Contains(essential, order[len(order)-1]) {
+			break
+		}
+
+		drop := order[len(order)-1]
+		order = order[:len(order)-1]
+
+		delete(result, drop)
+		affected[drop] = true
+	}
+
+	return result
+}
+
+// fits reports whether metadata with the given number of keys and size fits the budget.
+func (b *MetadataBudget) fits(keys, size int) bool {
+	return (b.MaxKeys <= 0 || keys <= b.MaxKeys) && (b.MaxTotalBytes <= 0 || size <= b.MaxTotalBytes)
+}
+
+// marker returns the value of the [MetadataTruncated] label that fits the budget, given the number
+// of keys and the size of the metadata with the marker key. It reports false if no value fits.
+func (b *MetadataBudget) marker(affected map[string]bool, keys, size int) (string, bool) {
+	if b.MaxKeys > 0 && keys > b.MaxKeys {
+		return "", false
+	}
+
+	limit := math.MaxInt
+
+	if b.MaxValueBytes > 0 {
+		limit = b.MaxValueBytes
+	}
+
+	if b.MaxTotalBytes > 0 {
+		limit = min(limit, b.MaxTotalBytes-size)
+	}
+
+	return truncationMarker(affected, limit)
+}
+
+// essentialKeys returns the output keys of the labels that are never dropped to make room
+// for the [MetadataTruncated] label.
+func (f *Formatter) essentialKeys() []string {
+	var keys []string
+
+	for _, key := range []string{MetadataIdentifier, MetadataErrorCode} {
+		if name, ok := f.systemKey(key); ok {
+			keys = append(keys, f.keyCase.convert(name))
+		}
+	}
+
+	return keys
+}
+
+// budgetOrder returns the keys of the metadata, most important first. Check [MetadataBudget.Priority].
+func (f *Formatter) budgetOrder(metadata map[string]string) []string {
+	outputKey := func(key string) string {
+		name, _ := f.systemKey(key)
+
+		return f.keyCase.convert(name)
+	}
+
+	var order []string
+
+	add := func(key string) {
+		if _, ok := metadata[key]; ok && !slices.Contains(order, key) {
+			order = append(order, key)
+		}
+	}
+
+	for _, key := range []string{
+		MetadataIdentifier, MetadataErrorCode, MetadataDomain,
+		MetadataRequestedCode, MetadataDefaultMessage, MetadataOperation,
+	} {
+		if _, ok := f.systemKey(key); ok {
+			add(outputKey(key))
+		}
+	}
+
+	for _, key := range f.budget.Priority {
+		add(f.keyCase.convert(key))
+	}
+
+	if _, ok := f.systemKey(MetadataOriginalError); ok {
+		add(outputKey(MetadataOriginalError))
+	}
+
+	rest := make([]string, 0, len(metadata))
+
+	for key := range metadata {
+		rest = append(rest, key)
+	}
+
+	sort.Strings(rest)
+
+	for _, key := range rest {
+		add(key)
+	}
+
+	return order
+}
+
+// truncateValue cuts the value to at most limit bytes, ending it with an ellipsis
+// and without splitting a multi-byte character. The ellipsis is left out if the
+// limit is too small to fit it.
+func truncateValue(value string, limit int) string {
+	if limit < len(truncationEllipsis) {
+		return cutValue(value, limit)
+	}
+
+	return cutValue(value, limit-len(truncationEllipsis)) + truncationEllipsis
+}
+
+// cutValue cuts the value to at most limit bytes, without splitting a multi-byte character.
+func cutValue(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+
+	return value[:cut]
+}
+
+// truncationMarker returns the value of the [MetadataTruncated] label of at most limit bytes.
+// Whenever the sorted keys do not fit, the last ones are
+// replaced with their count, e.g. "_original_error,+2", and it reports false if even the count does not fit.
+func truncationMarker(affected map[string]bool, limit int) (string, bool) {
+	keys := make([]string, 0, len(affected))
+
+	for key := range affected {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	if value := strings.Join(keys, ","); len(value) <= limit {
+		return value, true
+	}
+
+	for n := len(keys) - 1; n >= 0; n-- {
+		value := "+" + strconv.Itoa(len(keys)-n)
+		if n > 0 {
+			value = strings.Join(keys[:n], ",") + "," + value
+		}
+
+		if len(value) <= limit {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+func metadataSize(metadata map[string]string) int {
+	size := 0
+
+	for k, v := range metadata {
+		size += len(k) + len(v)
+	}
+
+	return size
+}
@@ -0,0 +1,207 @@
+package gerrors_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithMetadataBudget(t *testing.T) {
+	t.Parallel()
+
+	longValue := strings.Repeat("é", 50)
+
+	testCases := []struct {
+		name              string
+		budget            gerrors.MetadataBudget
+		expectedLabels    map[string]string
+		expectedMissing   []string
+		expectedTruncated string
+	}{
+		{
+			name:   "ellipsis",
+			budget: gerrors.MetadataBudget{MaxValueBytes: 10, Strategy: gerrors.TruncateEllipsis},
+			expectedLabels: map[string]string{
+				"query":                       "ééé…",
+				"user":                        "john",
+				gerrors.MetadataErrorCode:     "2",
+				gerrors.MetadataIdentifier:    "not-found",
+				gerrors.MetadataOriginalError: "sql: é…",
+			},
+			expectedTruncated: "+3",
+		},
+		{
+			name:   "limit smaller than the ellipsis",
+			budget: gerrors.MetadataBudget{MaxValueBytes: 2, Strategy: gerrors.TruncateEllipsis},
+			expectedLabels: map[string]string{
+				"query": "é",
+				"user":  "jo",
+			},
+			expectedTruncated: "",
+		},
+		{
+			name:   "hash",
+			budget: gerrors.MetadataBudget{MaxValueBytes: 30, Strategy: gerrors.TruncateHash},
+			expectedLabels: map[string]string{
+				"query": "sha256:",
+				"user":  "john",
+			},
+			expectedTruncated: "_default_message,+2",
+		},
+		{
+			name:              "drop",
+			budget:            gerrors.MetadataBudget{MaxValueBytes: 30, Strategy: gerrors.TruncateDrop},
+			expectedLabels:    map[string]string{"user": "john"},
+			expectedMissing:   []string{"query", gerrors.MetadataOriginalError},
+			expectedTruncated: "_default_message,+2",
+		},
+		{
+			name:              "max keys",
+			budget:            gerrors.MetadataBudget{MaxKeys: 4, Priority: []string{"user"}},
+			expectedLabels:    map[string]string{gerrors.MetadataIdentifier: "not-found", gerrors.MetadataErrorCode: "2"},
+			expectedMissing:   []string{"query", "user", gerrors.MetadataOriginalError},
+			expectedTruncated: "_operation,_original_error,query,user",
+		},
+		{
+			name:              "max total bytes keeps priority labels",
+			budget:            gerrors.MetadataBudget{MaxTotalBytes: 200, Priority: []string{"user"}},
+			expectedLabels:    map[string]string{"user": "john", gerrors.MetadataOperation: "repo"},
+			expectedMissing:   []string{"query", gerrors.MetadataOriginalError},
+			expectedTruncated: "_original_error,query",
+		},
+		{
+			name:   "marker within the limits",
+			budget: gerrors.MetadataBudget{MaxValueBytes: 8, MaxTotalBytes: 60},
+			expectedLabels: map[string]string{
+				gerrors.MetadataIdentifier: "not-f",
+				gerrors.MetadataErrorCode:  "2",
+			},
+			expectedMissing:   []string{"query", "user", gerrors.MetadataOriginalError},
+			expectedTruncated: "+6",
+		},
+		{
+			name:   "marker is left out rather than the identifier and code",
+			budget: gerrors.MetadataBudget{MaxValueBytes: 8, MaxTotalBytes: 35},
+			expectedLabels: map[string]string{
+				gerrors.MetadataIdentifier: "not-f",
+				gerrors.MetadataErrorCode:  "2",
+			},
+			expectedMissing: []string{gerrors.MetadataTruncated},
+		},
+		{
+			name:              "within budget",
+			budget:            gerrors.MetadataBudget{MaxValueBytes: 1000, MaxKeys: 100, MaxTotalBytes: 10000},
+			expectedLabels:    map[string]string{"query": longValue},
+			expectedMissing:   []string{gerrors.MetadataTruncated},
+			expectedTruncated: "",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := gerrors.NewFormatter(gerrors.WithMetadataBudget(tc.budget))
+			ge := f.New(errors.New("sql: "+longValue), gerrors.NotFound, "query", longValue, "user", "john")
+			err := gerrors.Wrap(ge, "repo")
+
+			st, _ := status.FromError(gerrors.GrpcError(err))
+
+			var metadata map[string]string
+
+			for _, detail := range st.Details() {
+				if info, ok := detail.(*errdetails.ErrorInfo); ok {
+					metadata = info.GetMetadata()
+				}
+			}
+
+			for k, v := range tc.expectedLabels {
+				if !strings.HasPrefix(metadata[k], v) {
+					t.Errorf("expected label %s=%s, got %q", k, v, metadata[k])
+				}
+			}
+
+			for _, k := range tc.expectedMissing {
+				if _, ok := metadata[k]; ok {
+					t.Errorf("expected label %s to be dropped, got %v", k, metadata)
+				}
+			}
+
+			if tc.expectedTruncated != "" && metadata[gerrors.MetadataTruncated] != tc.expectedTruncated {
+				t.Errorf("expected truncated marker %q, got %q", tc.expectedTruncated, metadata[gerrors.MetadataTruncated])
+			}
+
+			if tc.budget.MaxValueBytes > 0 {
+				for k, v := range metadata {
+					if len(v) > tc.budget.MaxValueBytes {
+						t.Errorf("expected label %s to have at most %d bytes, got %q", k, tc.budget.MaxValueBytes, v)
+					}
+				}
+			}
+
+			if tc.budget.MaxTotalBytes > 0 {
+				size := 0
+				for k, v := range metadata {
+					size += len(k) + len(v)
+				}
+
+				if size > tc.budget.MaxTotalBytes {
+					t.Errorf("expected at most %d bytes, got %d", tc.budget.MaxTotalBytes, size)
+				}
+			}
+
+			if tc.budget.MaxKeys > 0 && len(metadata) > tc.budget.MaxKeys {
+				t.Errorf("expected at most %d keys, got %v", tc.budget.MaxKeys, metadata)
+			}
+
+			var full *gerrors.GeneralError
+			if errors.As(err, &full) && full.Metadata()["query"] != longValue {
+				t.Errorf("expected full value in Metadata, got %q", full.Metadata()["query"])
+			}
+		})
+	}
+}
+
+func TestWithMetadataBudgetMessages(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		budget   gerrors.MetadataBudget
+		expected int
+	}{
+		{
+			name:     "defaults to max total bytes",
+			budget:   gerrors.MetadataBudget{MaxTotalBytes: 200},
+			expected: 200,
+		},
+		{
+			name:     "max message bytes",
+			budget:   gerrors.MetadataBudget{MaxTotalBytes: 200, MaxMessageBytes: 50},
+			expected: 50,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := gerrors.NewFormatter(gerrors.WithMetadataBudget(tc.budget))
+			err := f.New(errors.New(strings.Repeat("x", 100*1024)), gerrors.NotFound).Grpc()
+
+			st, _ := status.FromError(err)
+
+			if len(st.Message()) > tc.expected || !strings.HasSuffix(st.Message(), "…") {
+				t.Errorf("expected a truncated message of at most %d bytes, got %d bytes", tc.expected, len(st.Message()))
+			}
+		})
+	}
+}
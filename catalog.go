@@ -0,0 +1,403 @@
+package gerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ErrInvalidCatalog is returned whenever an error catalog fails validation.
+// Every violation found in the catalog is reported, wrapping this error.
+var ErrInvalidCatalog = errors.New("invalid error catalog")
+
+// Catalog is the declarative definition of a set of error codes.
+// It can be loaded from JSON using [ParseCatalog] and turned into a [Mapper].
+//
+//	{
+//	  "unknown_code": 1,
+//	  "domain": "users.example.com",
+//	  "errors": [
+//	    {"code": 1, "identifier": "unknown", "message": "unknown error", "grpc_code": "Unknown"},
+//	    {
+//	      "code": 100,
+//	      "identifier": "user-not-found",
+//	      "message": "user was not found",
+//	      "message_template": "user {{.user_id}} was not found",
+//	      "grpc_code": "NotFound",
+//	      "http_status": 404,
+//	      "retryable": false,
+//	      "severity": "warning",
+//	      "help_url": "https://example.com/errors#user-not-found",
+//	      "deprecated": "use account-not-found instead",
+//	      "labels": [
+//	        {"key": "user_id", "type": "string", "required": true},
+//	        {"key": "region", "type": "string", "description": "region the user was looked up in"}
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// Domain is the default domain of the entries, which can be overridden per entry.
+// Check [CoreDomainError] for more information.
+// Labels declare the label schema of an entry. Check [LabelSchema] for more information.
+type Catalog struct {
+	UnknownCode Code            `json:"unknown_code"`
+	Domain      string          `json:"domain,omitempty"`
+	Entries     []*CatalogEntry `json:"errors"`
+}
+
+// CatalogEntry is the definition of a single error code in a [Catalog].
+// It implements [CoreError] and all the optional Core interfaces.
+// GRPCCode accepts both Go names (e.g. "NotFound") and canonical names
+// (e.g. "NOT_FOUND") of gRPC codes, except OK, and defaults to Unknown.
+// If HTTPStatus is not set, it is derived from the gRPC code.
+type CatalogEntry struct {
+	Code            Code   `json:"code"`
+	Identifier      string `json:"identifier"`
+	Message         string `json:"message"`
+	MessageTemplate string `json:"message_template,omitempty"`
+	GRPCCode        string `json:"grpc_code,omitempty"`
+	HTTPStatus      int    `json:"http_status,omitempty"`
+	Retryable       bool   `json:"retryable,omitempty"`
+	Severity        string `json:"severity,omitempty"`
+	HelpURL         string `json:"help_url,omitempty"`
+	Deprecated      string `json:"deprecated,omitempty"`
+	Domain          string `json:"domain,omitempty"`
+	Reason          string `json:"reason,omitempty"`
+
+	Labels                []LabelSpec `json:"labels,omitempty"`
+	AllowUndeclaredLabels bool        `json:"allow_undeclared_labels,omitempty"`
+
+	// domain and grpcCode are resolved once the catalog is loaded. Check [Catalog.resolve].
+	domain   string
+	grpcCode codes.Code
+}
+
+// ParseCatalog reads a JSON error catalog, resolves the domain and gRPC code of its entries
+// and validates it. Check [Catalog] for the format and [Catalog.Validate] for the validation rules.
+func ParseCatalog(r io.Reader) (*Catalog, error) {
+	var c Catalog
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCatalog, err)
+	}
+
+	c.resolve()
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// LoadCatalog reads a JSON error catalog, validates it and returns a [Mapper]
+// that translates the catalog codes to their [CatalogEntry].
+func LoadCatalog(r io.Reader) (*Mapper, error) {
+	c, err := ParseCatalog(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Mapper(), nil
+}
+
+// LoadCatalogFS is the same as [LoadCatalog], but it reads the catalog from
+// the file system, e.g. an [embed.FS].
+func LoadCatalogFS(fsys fs.FS, name string) (*Mapper, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return LoadCatalog(f)
+}
+
+// Validate checks the catalog and reports every violation, joined together.
+// A valid catalog has unique codes other than [InheritCode], non-empty and unique identifiers,
+// valid message templates, gRPC code names, HTTP statuses and label schemas,
+// and an entry for its unknown code. Unless undeclared labels are allowed, the
+// message template of an entry with a label schema can only refer to declared labels.
+// The catalog is not modified.
+func (c *Catalog) Validate() error {
+	var errs []error
+
+	codesSeen := make(map[Code]bool, len(c.Entries))
+	identifiers := make(map[string]bool, len(c.Entries))
+
+	for i, entry := range c.Entries {
+		if entry == nil {
+			errs = append(errs, fmt.Errorf("%w: entry %d is empty", ErrInvalidCatalog, i))
+
+			continue
+		}
+
+		if codesSeen[entry.Code] {
+			errs = append(errs, fmt.Errorf("%w: duplicate code %d", ErrInvalidCatalog, entry.Code))
+		}
+
+		if entry.Code == InheritCode {
+			errs = append(errs, fmt.Errorf("%w: code %d is reserved for InheritCode", ErrInvalidCatalog, entry.Code))
+		}
+
+		codesSeen[entry.Code] = true
+
+		if entry.Identifier == "" {
+			errs = append(errs, fmt.Errorf("%w: code %d has no identifier", ErrInvalidCatalog, entry.Code))
+		} else if identifiers[entry.Identifier] {
+			errs = append(errs, fmt.Errorf("%w: duplicate identifier %q", ErrInvalidCatalog, entry.Identifier))
+		}
+
+		identifiers[entry.Identifier] = true
+
+		if grpcCode, ok := parseGRPCCode(entry.GRPCCode); !ok {
+			errs = append(errs, fmt.Errorf("%w: code %d has invalid gRPC code %q",
+				ErrInvalidCatalog, entry.Code, entry.GRPCCode))
+		} else if grpcCode == codes.OK {
+			errs = append(errs, fmt.Errorf("%w: code %d cannot have gRPC code OK", ErrInvalidCatalog, entry.Code))
+		}
+
+		if entry.MessageTemplate != "" {
+			if mt := getMessageTemplate(entry.MessageTemplate); mt.err != nil {
+				errs = append(errs, fmt.Errorf("%w: code %d has invalid message template: %w",
+					ErrInvalidCatalog, entry.Code, mt.err))
+			}
+		}
+
+		errs = append(errs, entry.validateLabels()...)
+
+		if entry.HTTPStatus != 0 && http.StatusText(entry.HTTPStatus) == "" {
+			errs = append(errs, fmt.Errorf("%w: code %d has invalid HTTP status %d",
+				ErrInvalidCatalog, entry.Code, entry.HTTPStatus))
+		}
+	}
+
+	if !codesSeen[c.UnknownCode] {
+		errs = append(errs, fmt.Errorf("%w: unknown code %d has no entry", ErrInvalidCatalog, c.UnknownCode))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Mapper returns a [Mapper] that translates the catalog codes to their [CatalogEntry],
+// resolving the domain and gRPC code of the entries. The catalog should be validated
+// beforehand. Entries are usable even if it is not, e.g. an invalid gRPC code is
+// translated to Unknown.
+func (c *Catalog) Mapper() *Mapper {
+	c.resolve()
+
+	mapping := make(map[Code]CoreError, len(c.Entries))
+
+	for _, entry := range c.Entries {
+		if entry != nil {
+			mapping[entry.Code] = entry
+		}
+	}
+
+	return NewMapper(c.UnknownCode, mapping)
+}
+
+// resolve sets the domain of every entry to its own domain or, if it has none, to the
+// catalog's domain, and parses their gRPC code once.
+func (c *Catalog) resolve() {
+	for _, entry := range c.Entries {
+		if entry == nil {
+			continue
+		}
+
+		entry.domain = c.domainOf(entry)
+		entry.grpcCode = entry.parseGRPCCode()
+	}
+}
+
+// validateLabels returns the violations of the entry's label schema.
+func (e *CatalogEntry) validateLabels() []error {
+	schema := e.GetLabelSchema()
+	if schema == nil {
+		return nil
+	}
+
+	var errs []error
+
+	if err := schema.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("%w: code %d has invalid labels: %w", ErrInvalidCatalog, e.Code, err))
+	}
+
+	if schema.AllowUndeclared {
+		return errs
+	}
+
+	for _, key := range e.LabelKeys() {
+		if _, ok := schema.Lookup(key); !ok {
+			errs = append(errs, fmt.Errorf("%w: code %d has undeclared label %q in its message template",
+				ErrInvalidCatalog, e.Code, key))
+		}
+	}
+
+	return errs
+}
+
+// domainOf returns the entry's domain, or the catalog's domain if the entry has none.
+func (c *Catalog) domainOf(entry *CatalogEntry) string {
+	if entry.Domain != "" {
+		return entry.Domain
+	}
+
+	return c.Domain
+}
+
+// LabelKeys returns the label keys that are referenced by the entry's message
+// template, in the order of their first appearance.
+func (e *CatalogEntry) LabelKeys() []string {
+	if e.MessageTemplate == "" {
+		return nil
+	}
+
+	mt := getMessageTemplate(e.MessageTemplate)
+	if mt.err != nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(mt.fields))
+	seen := make(map[string]bool, len(mt.fields))
+
+	for _, field := range mt.fields {
+		if seen[field] {
+			continue
+		}
+
+		seen[field] = true
+		keys = append(keys, field)
+	}
+
+	return keys
+}
+
+// GetInternalCode is part of CoreError interface implementation.
+func (e *CatalogEntry) GetInternalCode() Code {
+	return e.Code
+}
+
+// GetIdentifier is part of CoreError interface implementation.
+func (e *CatalogEntry) GetIdentifier() string {
+	return e.Identifier
+}
+
+// GetDefaultMessage is part of CoreError interface implementation.
+func (e *CatalogEntry) GetDefaultMessage() string {
+	return e.Message
+}
+
+// GetMessageTemplate is part of CoreMessageTemplate interface implementation.
+func (e *CatalogEntry) GetMessageTemplate() string {
+	return e.MessageTemplate
+}
+
+// GetGRPCCode is part of CoreGRPCError interface implementation.
+// Invalid gRPC codes and OK, which cannot describe an error, are translated to Unknown.
+func (e *CatalogEntry) GetGRPCCode() codes.Code {
+	// Resolved entries never have OK, so it means the entry is not resolved yet.
+	if e.grpcCode != codes.OK {
+		return e.grpcCode
+	}
+
+	return e.parseGRPCCode()
+}
+
+// parseGRPCCode returns the gRPC code of the entry. Check [CatalogEntry.GetGRPCCode].
+func (e *CatalogEntry) parseGRPCCode() codes.Code {
+	grpcCode, ok := parseGRPCCode(e.GRPCCode)
+	if !ok || grpcCode == codes.OK {
+		return codes.Unknown
+	}
+
+	return grpcCode
+}
+
+// GetHTTPStatus is part of CoreHTTPError interface implementation.
+func (e *CatalogEntry) GetHTTPStatus() int {
+	if e.HTTPStatus != 0 {
+		return e.HTTPStatus
+	}
+
+	return httpStatusFromGRPC(e.GetGRPCCode())
+}
+
+// IsRetryable is part of CoreRetryableError interface implementation.
+func (e *CatalogEntry) IsRetryable() bool {
+	return e.Retryable
+}
+
+// GetSeverity is part of CoreSeverityError interface implementation.
+func (e *CatalogEntry) GetSeverity() string {
+	return e.Severity
+}
+
+// GetHelpURL is part of CoreHelpError interface implementation.
+func (e *CatalogEntry) GetHelpURL() string {
+	return e.HelpURL
+}
+
+// GetDeprecation is part of CoreDeprecatedError interface implementation.
+func (e *CatalogEntry) GetDeprecation() string {
+	return e.Deprecated
+}
+
+// GetReason is part of CoreReasonError interface implementation.
+func (e *CatalogEntry) GetReason() string {
+	return e.Reason
+}
+
+// GetDomain is part of CoreDomainError interface implementation.
+// It returns the entry's domain, or the catalog's domain if the entry has none
+// once the catalog is loaded by [ParseCatalog] or [Catalog.Mapper].
+func (e *CatalogEntry) GetDomain() string {
+	if e.domain != "" {
+		return e.domain
+	}
+
+	return e.Domain
+}
+
+// GetLabelSchema is part of CoreLabelSchema interface implementation.
+// It returns nil if the entry declares no labels.
+func (e *CatalogEntry) GetLabelSchema() *LabelSchema {
+	if len(e.Labels) == 0 {
+		return nil
+	}
+
+	return &LabelSchema{Labels: e.Labels, AllowUndeclared: e.AllowUndeclaredLabels}
+}
+
+// parseGRPCCode translates the name of a gRPC code to codes.Code.
+// Both Go names (e.g. NotFound) and canonical names (e.g. NOT_FOUND) are
+// accepted. An empty name is translated to codes.Unknown.
+func parseGRPCCode(name string) (codes.Code, bool) {
+	if name == "" {
+		return codes.Unknown, true
+	}
+
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if c.String() == name {
+			return c, true
+		}
+	}
+
+	var c codes.Code
+	if err := c.UnmarshalJSON([]byte(strconv.Quote(name))); err != nil {
+		return codes.Unknown, false
+	}
+
+	return c, true
+}
@@ -0,0 +1,204 @@
+package gerrors_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/seinshah/gerrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testCatalog = `{
+  "unknown_code": 1,
+  "errors": [
+    {"code": 1, "identifier": "unknown", "message": "unknown error"},
+    {
+      "code": 100,
+      "identifier": "user-not-found",
+      "message": "user was not found",
+      "message_template": "user {{.user_id}} was not found",
+      "grpc_code": "NOT_FOUND",
+      "retryable": true,
+      "severity": "warning",
+      "help_url": "https://example.com/errors#user-not-found",
+      "deprecated": "use account-not-found instead"
+    },
+    {"code": 101, "identifier": "quota", "message": "quota exceeded", "grpc_code": "ResourceExhausted", "http_status": 429}
+  ]
+}`
+
+func TestLoadCatalog(t *testing.T) {
+	t.Parallel()
+
+	mapper, err := gerrors.LoadCatalogFS(fstest.MapFS{"errors.json": {Data: []byte(testCatalog)}}, "errors.json")
+	if err != nil {
+		t.Fatalf("unexpected error loading catalog: %v", err)
+	}
+
+	core := mapper.Lookup(gerrors.Code(100))
+
+	entry, ok := core.(*gerrors.CatalogEntry)
+	if !ok {
+		t.Fatalf("expected catalog entry, got %T", core)
+	}
+
+	if entry.GetGRPCCode() != codes.NotFound || entry.GetHTTPStatus() != 404 || !entry.IsRetryable() ||
+		entry.GetSeverity() != "warning" || entry.GetDeprecation() == "" {
+		t.Errorf("unexpected catalog entry: %+v", entry)
+	}
+
+	if quota, _ := mapper.Lookup(gerrors.Code(101)).(gerrors.CoreHTTPError); quota.GetHTTPStatus() != 429 {
+		t.Errorf("expected explicit HTTP status to be used")
+	}
+
+	if mapper.Lookup(gerrors.Code(5)).GetIdentifier() != "unknown" {
+		t.Errorf("expected unmapped code to fall back to unknown")
+	}
+
+	f := gerrors.NewFormatter(gerrors.WithLookuper(mapper))
+	gerr := f.New(nil, gerrors.Code(100), "user_id", 7)
+
+	if gerr.DefaultMessage() != "user 7 was not found" {
+		t.Errorf("unexpected default message: %s", gerr.DefaultMessage())
+	}
+
+	st, _ := status.FromError(gerr.Grpc())
+	if st.Code() != codes.NotFound || len(st.Details()) != 2 {
+		t.Fatalf("unexpected gRPC status: %v", st)
+	}
+
+	if help, ok := st.Details()[1].(*errdetails.Help); !ok || help.GetLinks()[0].GetUrl() != entry.HelpURL {
+		t.Errorf("expected help detail, got %v", st.Details()[1])
+	}
+}
+
+func TestLoadCatalogValidation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		catalog  string
+		expected []string
+	}{
+		{
+			name:     "malformed",
+			catalog:  `{"errors": [}`,
+			expected: []string{"invalid character"},
+		},
+		{
+			name:     "unknown field",
+			catalog:  `{"unknown_code": 1, "errors": [{"code": 1, "identifier": "a", "grpc": "NotFound"}]}`,
+			expected: []string{"unknown field"},
+		},
+		{
+			name: "invalid entries",
+			catalog: `{"unknown_code": 9, "errors": [
+				{"code": 1, "identifier": "a", "grpc_code": "Missing"},
+				{"code": 1, "identifier": "a", "http_status": 999},
+				{"code": 2},
+				{"code": 3, "identifier": "c", "grpc_code": "OK"},
+				{"code": -1, "identifier": "inherit"}
+			]}`,
+			expected: []string{
+				"duplicate code 1",
+				`duplicate identifier "a"`,
+				`invalid gRPC code "Missing"`,
+				"invalid HTTP status 999",
+				"code 2 has no identifier",
+				"code 3 cannot have gRPC code OK",
+				"code -1 is reserved for InheritCode",
+				"unknown code 9 has no entry",
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := gerrors.LoadCatalog(strings.NewReader(tc.catalog))
+			if !errors.Is(err, gerrors.ErrInvalidCatalog) {
+				t.Fatalf("expected invalid catalog error, got %v", err)
+			}
+
+			for _, msg := range tc.expected {
+				if !strings.Contains(err.Error(), msg) {
+					t.Errorf("expected error to contain %q, got %v", msg, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCatalogMapperWithoutValidation(t *testing.T) {
+	t.Parallel()
+
+	c := &gerrors.Catalog{
+		UnknownCode: gerrors.Unknown,
+		Domain:      "users.example.com",
+		Entries: []*gerrors.CatalogEntry{
+			{Code: gerrors.Unknown, Identifier: "unknown", Message: "unknown error"},
+			{Code: 100, Identifier: "user-not-found", Message: "user was not found", GRPCCode: "NOT_FOUND"},
+			{Code: 101, Identifier: "ok", Message: "not really ok", GRPCCode: "OK"},
+		},
+	}
+
+	f := gerrors.NewFormatter(gerrors.WithLookuper(c.Mapper()))
+
+	testCases := []struct {
+		code       gerrors.Code
+		grpcCode   codes.Code
+		httpStatus int
+	}{
+		{code: 100, grpcCode: codes.NotFound, httpStatus: 404},
+		{code: 101, grpcCode: codes.Unknown, httpStatus: 500},
+	}
+
+	for _, tc := range testCases {
+		err := gerrors.GrpcError(f.New(nil, tc.code))
+		if err == nil {
+			t.Fatalf("expected code %d to produce a gRPC error", tc.code)
+		}
+
+		if status.Code(err) != tc.grpcCode {
+			t.Errorf("expected gRPC code %s for code %d, got %s", tc.grpcCode, tc.code, status.Code(err))
+		}
+
+		entry, _ := c.Mapper().Lookup(tc.code).(*gerrors.CatalogEntry)
+		if entry.GetHTTPStatus() != tc.httpStatus || entry.GetDomain() != "users.example.com" {
+			t.Errorf("unexpected HTTP status %d or domain %q for code %d",
+				entry.GetHTTPStatus(), entry.GetDomain(), tc.code)
+		}
+	}
+}
+
+func TestCatalogValidateDoesNotResolve(t *testing.T) {
+	t.Parallel()
+
+	entry := &gerrors.CatalogEntry{Code: 100, Identifier: "user-not-found", GRPCCode: "NOT_FOUND"}
+	c := &gerrors.Catalog{
+		UnknownCode: 100,
+		Domain:      "users.example.com",
+		Entries:     []*gerrors.CatalogEntry{entry},
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error validating catalog: %v", err)
+	}
+
+	if entry.GetDomain() != "" || entry.GetGRPCCode() != codes.NotFound {
+		t.Errorf("expected validation not to resolve the domain, got %q", entry.GetDomain())
+	}
+
+	c.Mapper()
+
+	if entry.GetDomain() != "users.example.com" || entry.GetGRPCCode() != codes.NotFound {
+		t.Errorf("expected the mapper to resolve the domain, got %q", entry.GetDomain())
+	}
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/seinshah/gerrors"
+)
+
+// initialisms are the words that are kept upper-case in generated Go names.
+var initialisms = map[string]bool{
+	"api":  true,
+	"grpc": true,
+	"http": true,
+	"id":   true,
+	"ip":   true,
+	"json": true,
+	"sql":  true,
+	"url":  true,
+	"uuid": true,
+}
+
+// reservedParams are the names of the constructor parameters that are not labels.
+var reservedParams = map[string]bool{
+	"f":         true,
+	"err":       true,
+	"keyValues": true,
+}
+
+var errInvalidName = errors.New("cannot generate a valid Go name")
+
+type genFile struct {
+	Source     string
+	Package    string
+	UnknownRef string
+	Constants  bool
+	ImportTime bool
+	Entries    []genEntry
+}
+
+type genEntry struct {
+	Const           string
+	Ref             string
+	Func            string
+	Code            int
+	Identifier      string
+	Message         string
+	MessageTemplate string
+	GRPCCode        string
+	HTTPStatus      int
+	Retryable       bool
+	Severity        string
+	HelpURL         string
+	Deprecated      string
+	Domain          string
+	Reason          string
+	Labels          *gerrors.LabelSchema
+	Params          []genParam
+}
+
+type genParam struct {
+	Name string
+	Key  string
+	Type string
+}
+
+// paramTypes are the Go types of the constructor parameters of the typed labels.
+var paramTypes = map[gerrors.LabelType]string{
+	gerrors.LabelTypeAny:      "any",
+	gerrors.LabelTypeString:   "string",
+	gerrors.LabelTypeInteger:  "int",
+	gerrors.LabelTypeNumber:   "float64",
+	gerrors.LabelTypeBoolean:  "bool",
+	gerrors.LabelTypeDuration: "time.Duration",
+	gerrors.LabelTypeTime:     "time.Time",
+}
+
+// labelTypeNames are the names of the gerrors constants of the label types.
+var labelTypeNames = map[gerrors.LabelType]string{
+	gerrors.LabelTypeAny:      "LabelTypeAny",
+	gerrors.LabelTypeString:   "LabelTypeString",
+	gerrors.LabelTypeInteger:  "LabelTypeInteger",
+	gerrors.LabelTypeNumber:   "LabelTypeNumber",
+	gerrors.LabelTypeBoolean:  "LabelTypeBoolean",
+	gerrors.LabelTypeDuration: "LabelTypeDuration",
+	gerrors.LabelTypeTime:     "LabelTypeTime",
+}
+
+var fileTemplate = template.Must(template.New("file").Funcs(template.FuncMap{
+	"comment": func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	},
+	"labeltype": func(t gerrors.LabelType) string {
+		return "gerrors." + labelTypeNames[t]
+	},
+}).Parse(`// Code generated by gerrors-gen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .ImportTime}}
+	"time"
+
+{{end}}
+	"github.com/seinshah/gerrors"
+	"google.golang.org/grpc/codes"
+)
+
+{{- if .Constants}}
+const (
+{{- range .Entries}}
+	// {{.Const}} is the code of {{printf "%q" .Identifier}} error: {{comment .Message}}
+	{{- if .Deprecated}}
+	//
+	// Deprecated: {{comment .Deprecated}}
+	{{- end}}
+	{{.Const}} gerrors.Code = {{.Code}}
+{{end -}}
+)
+{{end}}
+// coreError is the generated implementation of gerrors.CoreError and its optional interfaces.
+type coreError struct {
+	code            gerrors.Code
+	identifier      string
+	message         string
+	messageTemplate string
+	grpcCode        codes.Code
+	httpStatus      int
+	retryable       bool
+	severity        string
+	helpURL         string
+	deprecation     string
+	domain          string
+	reason          string
+	labelSchema     *gerrors.LabelSchema
+}
+
+// Mapping returns the mapping of the generated codes to their details.
+func Mapping() map[gerrors.Code]gerrors.CoreError {
+	return map[gerrors.Code]gerrors.CoreError{
+	{{- range .Entries}}
+		{{.Ref}}: &coreError{
+			code:            {{.Ref}},
+			identifier:      {{printf "%q" .Identifier}},
+			message:         {{printf "%q" .Message}},
+			messageTemplate: {{printf "%q" .MessageTemplate}},
+			grpcCode:        codes.{{.GRPCCode}},
+			httpStatus:      {{.HTTPStatus}},
+			retryable:       {{.Retryable}},
+			severity:        {{printf "%q" .Severity}},
+			helpURL:         {{printf "%q" .HelpURL}},
+			deprecation:     {{printf "%q" .Deprecated}},
+			domain:          {{printf "%q" .Domain}},
+			reason:          {{printf "%q" .Reason}},
+			{{- with .Labels}}
+			labelSchema: &gerrors.LabelSchema{
+				Labels: []gerrors.LabelSpec{
+				{{- range .Labels}}
+					{{printf "{Key: %q, Type: %s, Required: %t, Description: %q}" .Key (labeltype .Type) .Required .Description}},
+				{{- end}}
+				},
+				AllowUndeclared: {{.AllowUndeclared}},
+			},
+			{{- else}}
+			labelSchema:     nil,
+			{{- end}}
+		},
+	{{- end}}
+	}
+}
+
+// NewMapper returns a mapper of the generated codes that falls back to {{.UnknownRef}}.
+func NewMapper() *gerrors.Mapper {
+	return gerrors.NewMapper({{.UnknownRef}}, Mapping())
+}
+{{range .Entries}}
+// {{.Func}} creates a new {{printf "%q" .Identifier}} error using the formatter.
+{{- if .Params}}
+// Parameters are added to the error as labels.
+{{- end}}
+{{- if .Deprecated}}
+//
+// Deprecated: {{comment .Deprecated}}
+{{- end}}
+func {{.Func}}(f *gerrors.Formatter, err error, {{range .Params}}{{.Name}} {{.Type}}, {{end}}keyValues ...any) *gerrors.GeneralError {
+	{{- if .Params}}
+	return f.New(err, {{.Ref}}, append([]any{ {{- range $i, $p := .Params}}{{if $i}}, {{end}}{{printf "%q" $p.Key}}, {{$p.Name}}{{end -}} }, keyValues...)...)
+	{{- else}}
+	return f.New(err, {{.Ref}}, keyValues...)
+	{{- end}}
+}
+{{end}}
+// GetInternalCode is part of gerrors.CoreError interface implementation.
+func (e *coreError) GetInternalCode() gerrors.Code {
+	return e.code
+}
+
+// GetIdentifier is part of gerrors.CoreError interface implementation.
+func (e *coreError) GetIdentifier() string {
+	return e.identifier
+}
+
+// GetDefaultMessage is part of gerrors.CoreError interface implementation.
+func (e *coreError) GetDefaultMessage() string {
+	return e.message
+}
+
+// GetMessageTemplate is part of gerrors.CoreMessageTemplate interface implementation.
+func (e *coreError) GetMessageTemplate() string {
+	return e.messageTemplate
+}
+
+// GetGRPCCode is part of gerrors.CoreGRPCError interface implementation.
+func (e *coreError) GetGRPCCode() codes.Code {
+	return e.grpcCode
+}
+
+// GetHTTPStatus is part of gerrors.CoreHTTPError interface implementation.
+func (e *coreError) GetHTTPStatus() int {
+	return e.httpStatus
+}
+
+// IsRetryable is part of gerrors.CoreRetryableError interface implementation.
+func (e *coreError) IsRetryable() bool {
+	return e.retryable
+}
+
+// GetSeverity is part of gerrors.CoreSeverityError interface implementation.
+func (e *coreError) GetSeverity() string {
+	return e.severity
+}
+
+// GetHelpURL is part of gerrors.CoreHelpError interface implementation.
+func (e *coreError) GetHelpURL() string {
+	return e.helpURL
+}
+
+// GetDeprecation is part of gerrors.CoreDeprecatedError interface implementation.
+func (e *coreError) GetDeprecation() string {
+	return e.deprecation
+}
+
+// GetDomain is part of gerrors.CoreDomainError interface implementation.
+func (e *coreError) GetDomain() string {
+	return e.domain
+}
+
+// GetReason is part of gerrors.CoreReasonError interface implementation.
+func (e *coreError) GetReason() string {
+	return e.reason
+}
+
+// GetLabelSchema is part of gerrors.CoreLabelSchema interface implementation.
+func (e *coreError) GetLabelSchema() *gerrors.LabelSchema {
+	return e.labelSchema
+}
+`))
+
+// generateFromCatalog reads the catalog and returns the formatted Go source
+// of the generated file, including the code constants.
+func generateFromCatalog(r io.Reader, pkg, source string) ([]byte, error) {
+	catalog, err := gerrors.ParseCatalog(r)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(catalog.Entries))
+	seen := make(map[string]string, len(catalog.Entries))
+
+	for i, entry := range catalog.Entries {
+		name := goName(entry.Identifier, true)
+		if !token.IsIdentifier(name) {
+			return nil, fmt.Errorf("%w from identifier %q", errInvalidName, entry.Identifier)
+		}
+
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("%w: identifiers %q and %q are both named %s",
+				errInvalidName, other, entry.Identifier, name)
+		}
+
+		seen[name] = entry.Identifier
+		names[i] = name
+	}
+
+	return generate(catalog, names, pkg, source, true)
+}
+
+// generate returns the formatted Go source of the generated file.
+// names are the Go names of the catalog entries' constants. If constants is
+// false, the constants are expected to be declared elsewhere in the package,
+// possibly with a local type, so they are converted to gerrors.Code when used.
+func generate(catalog *gerrors.Catalog, names []string, pkg, source string, constants bool) ([]byte, error) {
+	file := genFile{
+		Source:     source,
+		Package:    pkg,
+		UnknownRef: "",
+		Constants:  constants,
+		Entries:    make([]genEntry, 0, len(catalog.Entries)),
+	}
+
+	for i, entry := range catalog.Entries {
+		ref := names[i]
+		if !constants {
+			ref = "gerrors.Code(" + ref + ")"
+		}
+
+		if entry.Code == catalog.UnknownCode {
+			file.UnknownRef = ref
+		}
+
+		file.Entries = append(file.Entries, genEntry{
+			Const:           names[i],
+			Ref:             ref,
+			Func:            "Err" + names[i],
+			Code:            int(entry.Code),
+			Identifier:      entry.Identifier,
+			Message:         entry.Message,
+			MessageTemplate: entry.MessageTemplate,
+			GRPCCode:        entry.GetGRPCCode().String(),
+			HTTPStatus:      entry.GetHTTPStatus(),
+			Retryable:       entry.Retryable,
+			Severity:        entry.Severity,
+			HelpURL:         entry.HelpURL,
+			Deprecated:      entry.Deprecated,
+			Domain:          entry.GetDomain(),
+			Reason:          entry.GetReason(),
+			Labels:          entry.GetLabelSchema(),
+			Params:          params(entry),
+		})
+
+		for _, p := range file.Entries[len(file.Entries)-1].Params {
+			if strings.HasPrefix(p.Type, "time.") {
+				file.ImportTime = true
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+
+	if err := fileTemplate.Execute(&buf, file); err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// params returns the constructor parameters of the entry: the labels referenced by its
+// message template, followed by the rest of the required labels of its label schema.
+// Parameters are typed based on the label schema, and they are of type any otherwise.
+func params(entry *gerrors.CatalogEntry) []genParam {
+	keys := entry.LabelKeys()
+	schema := entry.GetLabelSchema()
+
+	if schema != nil {
+		for _, spec := range schema.Labels {
+			if spec.Required && !slices.Contains(keys, spec.Key) {
+				keys = append(keys, spec.Key)
+			}
+		}
+	}
+
+	result := make([]genParam, 0, len(keys))
+	used := make(map[string]bool, len(keys))
+
+	for _, key := range keys {
+		paramType := paramTypes[gerrors.LabelTypeAny]
+
+		if schema != nil {
+			if spec, ok := schema.Lookup(key); ok {
+				paramType = paramTypes[spec.Type]
+			}
+		}
+
+		name := goName(key, false)
+		if !token.IsIdentifier(name) || token.IsKeyword(name) || reservedParams[name] || used[name] {
+			name += "Label"
+		}
+
+		for used[name] {
+			name += "_"
+		}
+
+		used[name] = true
+
+		result = append(result, genParam{Name: name, Key: key, Type: paramType})
+	}
+
+	return result
+}
+
+// goName converts an identifier or a label key, e.g. "user-not-found" or "user_id",
+// to a Go name, e.g. "UserNotFound" or "userID". It is made of the same words as the
+// schema names and docs anchors of the identifier. Check [gerrors.IdentifierWords].
+func goName(s string, exported bool) string {
+	var sb strings.Builder
+
+	for i, word := range gerrors.IdentifierWords(s) {
+		switch {
+		case i == 0 && !exported:
+			sb.WriteString(word)
+		case initialisms[word]:
+			sb.WriteString(strings.ToUpper(word))
+		default:
+			runes := []rune(word)
+			sb.WriteString(strings.ToUpper(string(runes[0])) + string(runes[1:]))
+		}
+	}
+
+	name := sb.String()
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		prefix := "code"
+		if exported {
+			prefix = "Code"
+		}
+
+		name = prefix + name
+	}
+
+	return name
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFromCatalog(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/errors.json")
+	if err != nil {
+		t.Fatalf("failed to open catalog: %v", err)
+	}
+
+	defer f.Close()
+
+	src, err := generateFromCatalog(f, "errs", "testdata/errors.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	golden, err := os.ReadFile("testdata/errors_gen.golden")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	if !bytes.Equal(src, golden) {
+		t.Errorf("generated code does not match golden file:\n%s", src)
+	}
+}
+
+func TestGenerateInvalidCatalog(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		catalog string
+	}{
+		{
+			name:    "invalid catalog",
+			catalog: `{"unknown_code": 1, "errors": []}`,
+		},
+		{
+			name: "colliding names",
+			catalog: `{"unknown_code": 1, "errors": [
+				{"code": 1, "identifier": "not-found"},
+				{"code": 2, "identifier": "not_found"}
+			]}`,
+		},
+		{
+			name:    "invalid name",
+			catalog: `{"unknown_code": 1, "errors": [{"code": 1, "identifier": "--"}]}`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := generateFromCatalog(strings.NewReader(tc.catalog), "errs", "errors.json"); err == nil {
+				t.Errorf("expected error generating from catalog")
+			}
+		})
+	}
+}
+
+func TestGoName(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string][2]string{
+		"user-not-found": {"UserNotFound", "userNotFound"},
+		"user_id":        {"UserID", "userID"},
+		"HTTP status":    {"HTTPStatus", "httpStatus"},
+		"404-page":       {"Code404Page", "code404Page"},
+		"QUOTA_EXCEEDED": {"QuotaExceeded", "quotaExceeded"},
+	}
+
+	for input, expected := range testCases {
+		if name := goName(input, true); name != expected[0] {
+			t.Errorf("expected exported name of %q to be %s, got %s", input, expected[0], name)
+		}
+
+		if name := goName(input, false); name != expected[1] {
+			t.Errorf("expected unexported name of %q to be %s, got %s", input, expected[1], name)
+		}
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	t.Parallel()
+
+	out := filepath.Join(t.TempDir(), "errors_gen.go")
+	args := []string{"-catalog", "testdata/errors.json", "-out", out, "-package", "errs"}
+
+	if err := run(args); err != nil {
+		t.Fatalf("unexpected error generating: %v", err)
+	}
+
+	if err := run(append(args, "-check")); err != nil {
+		t.Errorf("expected generated file to be in sync: %v", err)
+	}
+
+	if err := os.WriteFile(out, []byte("package errs\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(append(args, "-check")); !errors.Is(err, errOutOfSync) {
+		t.Errorf("expected out of sync error, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+// Command gerrors-gen generates Go code from a gerrors error catalog.
+// The generated file contains typed Code constants, a CoreError implementation,
+// a ready to use Mapper and a constructor helper per code. It does not rely on
+// reflection or on reading the catalog at runtime.
+// Constructors take the labels of the message template and the required labels of
+// the label schema as parameters, typed after the schema, e.g. an "integer" label
+// becomes an int parameter.
+//
+// It is meant to be used with go:generate:
+//
+//	//go:generate go run github.com/seinshah/gerrors/cmd/gerrors-gen -catalog errors.json -out errors_gen.go -package errs
+//
+// As a lighter alternative to a catalog, codes and their metadata can be kept
+// in a Go const block, using directive comments:
+//
+//	const (
+//		// Unknown is returned when no information is available.
+//		//gerrors:unknown grpc=Unknown
+//		Unknown gerrors.Code = iota + 1
+//
+//		// NotFound is returned when the record does not exist.
+//		//gerrors:grpc=NotFound http=404 id=not-found msg="no record was found"
+//		NotFound
+//	)
+//
+//	//go:generate go run github.com/seinshah/gerrors/cmd/gerrors-gen -source codes.go
+//
+// In that case, the constants are not generated again, the package name defaults
+// to the one of the source file, and the output defaults to <source>_gerrors.go.
+// Supported directive keys are id, msg, tpl, grpc, http, retryable, severity,
+// help, deprecated and unknown. Use -type if the constants are not of a type named Code.
+//
+// Use -check in CI to fail whenever the generated file is out of sync with the catalog.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var errOutOfSync = errors.New("generated file is out of sync, run gerrors-gen again")
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "gerrors-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("gerrors-gen", flag.ContinueOnError)
+
+	catalogPath := fs.String("catalog", "", "path of the JSON error catalog")
+	sourcePath := fs.String("source", "", "path of the Go file with annotated code constants")
+	typeName := fs.String("type", "Code", "type name of the annotated code constants")
+	out := fs.String("out", "", "path of the generated Go file")
+	pkg := fs.String("package", os.Getenv("GOPACKAGE"), "package name of the generated file")
+	check := fs.Bool("check", false, "fail if the generated file is out of sync instead of writing it")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var (
+		src []byte
+		err error
+	)
+
+	switch {
+	case *catalogPath != "" && *sourcePath == "" && *pkg != "":
+		if *out == "" {
+			*out = "gerrors_gen.go"
+		}
+
+		src, err = generateFromCatalogFile(*catalogPath, *pkg)
+	case *sourcePath != "" && *catalogPath == "":
+		if *out == "" {
+			*out = strings.TrimSuffix(*sourcePath, ".go") + "_gerrors.go"
+		}
+
+		src, err = generateFromSource(*sourcePath, nil, *pkg, *typeName)
+	default:
+		fs.Usage()
+
+		return errors.New("either -catalog and -package, or -source is required")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if *check {
+		existing, err := os.ReadFile(*out)
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(existing, src) {
+			return fmt.Errorf("%w: %s", errOutOfSync, *out)
+		}
+
+		return nil
+	}
+
+	return os.WriteFile(*out, src, 0o600)
+}
+
+func generateFromCatalogFile(path, pkg string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return generateFromCatalog(f, pkg, path)
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/seinshah/gerrors"
+)
+
+// directivePrefix starts the comment lines that hold the metadata of a code.
+const directivePrefix = "//gerrors:"
+
+var (
+	errNoCodes          = errors.New("no constants of the code type were found")
+	errNoUnknownCode    = errors.New("no constant is marked as the unknown code")
+	errInvalidDirective = errors.New("invalid gerrors directive")
+	errInvalidConstant  = errors.New("cannot evaluate constant")
+)
+
+// sourceFile is the result of parsing a Go file with annotated code constants.
+type sourceFile struct {
+	pkg     string
+	catalog *gerrors.Catalog
+	names   []string
+
+	// values are the evaluated constants of the file, by name.
+	values map[string]constant.Value
+}
+
+// generateFromSource parses the Go file and returns the formatted Go source of
+// the mapping of its code constants. Constants of the code type are read from
+// const blocks, and their metadata from the directive comments above them:
+//
+//	const (
+//		// NotFound is returned when no record was found.
+//		//gerrors:grpc=NotFound http=404 id=not-found msg="no record was found"
+//		NotFound gerrors.Code = iota + 1
+//	)
+//
+// Supported directive keys are id, msg, tpl (message template), grpc, http,
+// retryable, severity, help, deprecated and unknown, which marks the fallback code.
+// If id is missing, it is derived from the constant name, and if msg is missing,
+// it is derived from the id.
+// Deprecation can also be declared by a "Deprecated:" paragraph in the documentation.
+func generateFromSource(filename string, src any, pkg, typeName string) ([]byte, error) {
+	sf, err := parseSource(filename, src, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	if pkg == "" {
+		pkg = sf.pkg
+	}
+
+	return generate(sf.catalog, sf.names, pkg, filename, false)
+}
+
+func parseSource(filename string, src any, typeName string) (*sourceFile, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	sf := &sourceFile{
+		pkg:     file.Name.Name,
+		catalog: &gerrors.Catalog{UnknownCode: 0, Entries: nil},
+		names:   nil,
+		values:  make(map[string]constant.Value),
+	}
+
+	unknownFound := false
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+
+		found, err := sf.parseConstBlock(gen, typeName)
+		if err != nil {
+			return nil, err
+		}
+
+		unknownFound = unknownFound || found
+	}
+
+	if len(sf.names) == 0 {
+		return nil, fmt.Errorf("%w: %s in %s", errNoCodes, typeName, filename)
+	}
+
+	if !unknownFound {
+		return nil, fmt.Errorf("%w in %s", errNoUnknownCode, filename)
+	}
+
+	if err := sf.catalog.Validate(); err != nil {
+		return nil, err
+	}
+
+	return sf, nil
+}
+
+// parseConstBlock adds the constants of the code type in the block to the catalog.
+// It reports whether any of them is marked as the unknown code.
+func (sf *sourceFile) parseConstBlock(gen *ast.GenDecl, typeName string) (bool, error) {
+	var (
+		lastType   ast.Expr
+		lastValues []ast.Expr
+		unknown    bool
+	)
+
+	for index, spec := range gen.Specs {
+		vs, _ := spec.(*ast.ValueSpec)
+
+		// Constants without type and value repeat the previous type and values.
+		if vs.Type != nil || len(vs.Values) > 0 {
+			lastType, lastValues = vs.Type, vs.Values
+		}
+
+		for i, name := range vs.Names {
+			var value constant.Value
+
+			if i < len(lastValues) {
+				v, err := evalConst(lastValues[i], index, sf.values)
+				if err != nil {
+					return false, fmt.Errorf("%w %s: %w", errInvalidConstant, name.Name, err)
+				}
+
+				value = v
+				sf.values[name.Name] = v
+			}
+
+			if !isCodeType(lastType, typeName) || name.Name == "_" {
+				continue
+			}
+
+			code, ok := constant.Int64Val(value)
+			if !ok {
+				return false, fmt.Errorf("%w %s: not an integer", errInvalidConstant, name.Name)
+			}
+
+			doc := vs.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+
+			entry, isUnknown, err := parseDirectives(name.Name, gerrors.Code(code), doc)
+			if err != nil {
+				return false, err
+			}
+
+			if isUnknown {
+				unknown = true
+				sf.catalog.UnknownCode = entry.Code
+			}
+
+			sf.catalog.Entries = append(sf.catalog.Entries, entry)
+			sf.names = append(sf.names, name.Name)
+		}
+	}
+
+	return unknown, nil
+}
+
+// parseDirectives builds the catalog entry of a constant from its documentation.
+func parseDirectives(name string, code gerrors.Code, comments *ast.CommentGroup) (*gerrors.CatalogEntry, bool, error) {
+	entry := &gerrors.CatalogEntry{
+		Code:       code,
+		Identifier: kebabCase(name),
+	}
+
+	var (
+		prose   []string
+		unknown bool
+	)
+
+	if comments != nil {
+		for _, c := range comments.List {
+			directive, ok := strings.CutPrefix(c.Text, directivePrefix)
+			if !ok {
+				prose = append(prose, strings.TrimSpace(strings.TrimPrefix(c.Text, "//")))
+
+				continue
+			}
+
+			pairs, err := splitDirective(directive)
+			if err != nil {
+				return nil, false, fmt.Errorf("%w on %s: %w", errInvalidDirective, name, err)
+			}
+
+			for _, pair := range pairs {
+				isUnknown, err := applyDirective(entry, pair[0], pair[1])
+				if err != nil {
+					return nil, false, fmt.Errorf("%w on %s: %w", errInvalidDirective, name, err)
+				}
+
+				unknown = unknown || isUnknown
+			}
+		}
+	}
+
+	text := strings.Join(prose, "\n")
+
+	if entry.Message == "" {
+		entry.Message = strings.ReplaceAll(entry.Identifier, "-", " ")
+	}
+
+	if entry.Deprecated == "" {
+		if _, deprecation, ok := strings.Cut(text, "Deprecated:"); ok {
+			entry.Deprecated = strings.TrimSuffix(strings.Join(strings.Fields(deprecation), " "), ".")
+		}
+	}
+
+	return entry, unknown, nil
+}
+
+func applyDirective(entry *gerrors.CatalogEntry, key, value string) (bool, error) {
+	var err error
+
+	switch key {
+	case "id":
+		entry.Identifier = value
+	case "msg":
+		entry.Message = value
+	case "tpl":
+		entry.MessageTemplate = value
+	case "grpc":
+		entry.GRPCCode = value
+	case "http":
+		entry.HTTPStatus, err = strconv.Atoi(value)
+	case "retryable":
+		entry.Retryable, err = parseFlag(value)
+	case "severity":
+		entry.Severity = value
+	case "help":
+		entry.HelpURL = value
+	case "deprecated":
+		entry.Deprecated = value
+	case "unknown":
+		return parseFlag(value)
+	default:
+		err = fmt.Errorf("unknown key %q", key)
+	}
+
+	return false, err
+}
+
+func parseFlag(value string) (bool, error) {
+	if value == "" {
+		return true, nil
+	}
+
+	return strconv.ParseBool(value)
+}
+
+// splitDirective splits a directive to its key value pairs. Values can be
+// quoted using Go syntax, and keys without values are flags.
+func splitDirective(directive string) ([][2]string, error) {
+	var pairs [][2]string
+
+	rest := strings.TrimSpace(directive)
+
+	for rest != "" {
+		end := strings.IndexAny(rest, "= ")
+		if end < 0 {
+			pairs = append(pairs, [2]string{rest, ""})
+
+			break
+		}
+
+		key := rest[:end]
+
+		if rest[end] == ' ' {
+			pairs = append(pairs, [2]string{key, ""})
+			rest = strings.TrimSpace(rest[end:])
+
+			continue
+		}
+
+		rest = rest[end+1:]
+
+		var value string
+
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value of %q: %w", key, err)
+			}
+
+			value, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+		}
+
+		pairs = append(pairs, [2]string{key, value})
+		rest = strings.TrimSpace(rest)
+	}
+
+	return pairs, nil
+}
+
+// evalConst evaluates a constant expression of a const block.
+// It supports literals, iota, references to previous constants of the
+// file, conversions, parentheses, and unary and binary operators.
+func evalConst(expr ast.Expr, iota int, values map[string]constant.Value) (constant.Value, error) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		v := constant.MakeFromLiteral(e.Value, e.Kind, 0)
+		if v.Kind() == constant.Unknown {
+			return nil, fmt.Errorf("invalid literal %s", e.Value)
+		}
+
+		return v, nil
+	case *ast.Ident:
+		if e.Name == "iota" {
+			return constant.MakeInt64(int64(iota)), nil
+		}
+
+		if v, ok := values[e.Name]; ok {
+			return v, nil
+		}
+
+		return nil, fmt.Errorf("unknown identifier %s", e.Name)
+	case *ast.ParenExpr:
+		return evalConst(e.X, iota, values)
+	case *ast.CallExpr:
+		if len(e.Args) != 1 {
+			return nil, errors.New("unsupported call expression")
+		}
+
+		return evalConst(e.Args[0], iota, values)
+	case *ast.UnaryExpr:
+		x, err := evalConst(e.X, iota, values)
+		if err != nil {
+			return nil, err
+		}
+
+		return constant.UnaryOp(e.Op, x, 0), nil
+	case *ast.BinaryExpr:
+		x, err := evalConst(e.X, iota, values)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := evalConst(e.Y, iota, values)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.Op == token.SHL || e.Op == token.SHR {
+			shift, _ := constant.Uint64Val(y)
+
+			return constant.Shift(x, e.Op, uint(shift)), nil
+		}
+
+		if e.Op == token.QUO {
+			return constant.BinaryOp(x, token.QUO_ASSIGN, y), nil
+		}
+
+		return constant.BinaryOp(x, e.Op, y), nil
+	default:
+		return nil, fmt.Errorf("unsupported expression %T", expr)
+	}
+}
+
+// isCodeType reports whether the type expression is the code type, either
+// as a local type (e.g. Code) or as a selector (e.g. gerrors.Code).
+func isCodeType(expr ast.Expr, typeName string) bool {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name == typeName
+	case *ast.SelectorExpr:
+		return t.Sel.Name == typeName
+	default:
+		return false
+	}
+}
+
+// kebabCase converts a Go name to an identifier. e.g. NotFound to not-found.
+func kebabCase(name string) string {
+	var sb strings.Builder
+
+	runes := []rune(name)
+
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			sb.WriteByte('-')
+		}
+
+		sb.WriteRune(unicode.ToLower(r))
+	}
+
+	return sb.String()
+}
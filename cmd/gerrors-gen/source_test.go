@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateFromSource(t *testing.T) {
+	t.Parallel()
+
+	src, err := generateFromSource("testdata/codes.go", nil, "", "Code")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	golden, err := os.ReadFile("testdata/codes_gerrors.golden")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	if !bytes.Equal(src, golden) {
+		t.Errorf("generated code does not match golden file:\n%s", src)
+	}
+}
+
+func TestParseSourceCodes(t *testing.T) {
+	t.Parallel()
+
+	sf, err := parseSource("testdata/codes.go", nil, "Code")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]int{"Unknown": 1, "NotFound": 101, "QuotaExceeded": 102, "LegacyStorage": 103}
+
+	if len(sf.names) != len(expected) {
+		t.Fatalf("expected %d codes, got %v", len(expected), sf.names)
+	}
+
+	for i, name := range sf.names {
+		if code := int(sf.catalog.Entries[i].Code); code != expected[name] {
+			t.Errorf("expected %s to be %d, got %d", name, expected[name], code)
+		}
+	}
+
+	if sf.catalog.UnknownCode != 1 {
+		t.Errorf("expected unknown code to be 1, got %d", sf.catalog.UnknownCode)
+	}
+}
+
+func TestGenerateInvalidSource(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		source   string
+		expected error
+	}{
+		{
+			name:     "no codes",
+			source:   "package errs\n\nconst Unknown = 1\n",
+			expected: errNoCodes,
+		},
+		{
+			name:     "no unknown code",
+			source:   "package errs\n\nconst (\n\tUnknown Code = iota\n)\n",
+			expected: errNoUnknownCode,
+		},
+		{
+			name:     "unknown directive key",
+			source:   "package errs\n\nconst (\n\t//gerrors:unknown color=red\n\tUnknown Code = 1\n)\n",
+			expected: errInvalidDirective,
+		},
+		{
+			name:     "invalid quoted value",
+			source:   "package errs\n\nconst (\n\t//gerrors:unknown msg=\"oops\n\tUnknown Code = 1\n)\n",
+			expected: errInvalidDirective,
+		},
+		{
+			name:     "unsupported expression",
+			source:   "package errs\n\nconst (\n\t//gerrors:unknown\n\tUnknown Code = len(\"a\", \"b\")\n)\n",
+			expected: errInvalidConstant,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := generateFromSource("codes.go", tc.source, "", "Code"); !errors.Is(err, tc.expected) {
+				t.Errorf("expected error %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestSplitDirective(t *testing.T) {
+	t.Parallel()
+
+	pairs, err := splitDirective(` grpc=NotFound unknown msg="not found, \"really\"" http=404`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := [][2]string{{"grpc", "NotFound"}, {"unknown", ""}, {"msg", `not found, "really"`}, {"http", "404"}}
+
+	if len(pairs) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, pairs)
+	}
+
+	for i := range expected {
+		if pairs[i] != expected[i] {
+			t.Errorf("expected pair %d to be %v, got %v", i, expected[i], pairs[i])
+		}
+	}
+}
+
+func TestKebabCase(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"NotFound":       "not-found",
+		"HTTPStatus":     "http-status",
+		"UserIDNotFound": "user-id-not-found",
+		"Unknown":        "unknown",
+	}
+
+	for input, expected := range testCases {
+		if result := kebabCase(input); result != expected {
+			t.Errorf("expected %s to be %s, got %s", input, expected, result)
+		}
+	}
+}
+
+func TestRunSource(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "codes.go")
+
+	content, err := os.ReadFile("testdata/codes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(source, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run([]string{"-source", source, "-package", ""}); err != nil {
+		t.Fatalf("unexpected error generating: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "codes_gerrors.go")); err != nil {
+		t.Errorf("expected generated file next to the source: %v", err)
+	}
+
+	if err := run([]string{"-source", source, "-catalog", "testdata/errors.json"}); err == nil {
+		t.Errorf("expected error when both -source and -catalog are set")
+	}
+}
@@ -0,0 +1,32 @@
+package errs
+
+// Code is the error code of the package.
+type Code int
+
+// Service is the first code of the service.
+const Service = 100
+
+const (
+	// Unknown is returned when no information is available for this error.
+	//gerrors:unknown
+	Unknown Code = iota + 1
+
+	// NotFound is returned when the user was not found.
+	//gerrors:grpc=NotFound id=user-not-found tpl="user {{.user_id}} was not found"
+	//gerrors:severity=warning help=https://example.com/errors#user-not-found
+	NotFound Code = Service + iota
+
+	// QuotaExceeded is returned when the quota of the user is exhausted.
+	//gerrors:grpc=RESOURCE_EXHAUSTED retryable msg="quota exceeded"
+	QuotaExceeded
+
+	// LegacyStorage is returned when the storage fails.
+	//
+	// Deprecated: use
+	// storage instead.
+	//gerrors:grpc=Internal http=502
+	LegacyStorage
+
+	_
+	notACode = "not a code"
+)
@@ -0,0 +1,163 @@
+// Command gerrors provides tooling around gerrors error catalogs.
+//
+//	gerrors docs -catalog errors.json -format markdown -out ERRORS.md
+//	gerrors openapi -catalog errors.json -out errors.openapi.json
+//	gerrors jsonschema -catalog errors.json -out error.schema.json
+//	gerrors typescript -catalog errors.json -out errors.ts
+//
+// The docs command renders a reference of every error code of the catalog,
+// including its anchor, gRPC and HTTP mapping, default message, retryability,
+// deprecation and example payloads. Anchors are derived from the identifiers,
+// so help URLs of the catalog can safely point at them, and identifiers that would
+// share an anchor are rejected. The output file is only written once the docs are rendered.
+//
+// The openapi command writes an OpenAPI 3.1 document with a schema and a response
+// object per error code, and the jsonschema command writes a standalone JSON Schema
+// of the error payload. The typescript command writes a TypeScript module with the
+// codes, identifiers, reasons and labels of the errors, and type guards to parse them.
+// With -aip193, these commands describe the UPPER_SNAKE_CASE reasons and lowerCamelCase
+// metadata keys that are sent by formatters in strict AIP-193 mode.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/seinshah/gerrors"
+)
+
+var errUsage = errors.New("usage: gerrors <docs|openapi|jsonschema|typescript> -catalog <file> [flags]")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "gerrors:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "docs":
+		return runDocs(args[1:], stdout)
+	case "openapi", "jsonschema", "typescript":
+		return runSchema(args[0], args[1:], stdout)
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+}
+
+func runDocs(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("gerrors docs", flag.ContinueOnError)
+
+	catalogPath := fs.String("catalog", "", "path of the JSON error catalog (required)")
+	format := fs.String("format", string(gerrors.DocsMarkdown), "output format, markdown or html")
+	title := fs.String("title", "Error reference", "title of the reference")
+	out := fs.String("out", "", "path of the output file, defaults to stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *catalogPath == "" {
+		fs.Usage()
+
+		return errUsage
+	}
+
+	switch gerrors.DocsFormat(*format) {
+	case gerrors.DocsMarkdown, gerrors.DocsHTML:
+	default:
+		return fmt.Errorf("%w: unknown docs format %q", errUsage, *format)
+	}
+
+	mapper, err := loadCatalog(*catalogPath)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*out, stdout, func(w io.Writer) error {
+		return gerrors.WriteDocs(w, mapper, gerrors.DocsFormat(*format), gerrors.WithDocsTitle(*title))
+	})
+}
+
+func runSchema(command string, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("gerrors "+command, flag.ContinueOnError)
+
+	catalogPath := fs.String("catalog", "", "path of the JSON error catalog (required)")
+	title := fs.String("title", "Errors", "title of the document")
+	version := fs.String("version", "1.0.0", "version of the OpenAPI document")
+	out := fs.String("out", "", "path of the output file, defaults to stdout")
+	aip193 := fs.Bool("aip193", false, "describe the errors as sent by a formatter in strict AIP-193 mode")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *catalogPath == "" {
+		fs.Usage()
+
+		return errUsage
+	}
+
+	mapper, err := loadCatalog(*catalogPath)
+	if err != nil {
+		return err
+	}
+
+	opts := []gerrors.SchemaOption{gerrors.WithSchemaTitle(*title), gerrors.WithSchemaVersion(*version)}
+
+	if *aip193 {
+		f, err := gerrors.NewFormatterE(gerrors.WithLookuper(mapper), gerrors.WithAIP193())
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, gerrors.WithSchemaFormatter(f))
+	}
+
+	return writeOutput(*out, stdout, func(w io.Writer) error {
+		switch command {
+		case "jsonschema":
+			return gerrors.WriteJSONSchema(w, mapper, opts...)
+		case "typescript":
+			return gerrors.WriteTypeScript(w, mapper, opts...)
+		default:
+			return gerrors.WriteOpenAPI(w, mapper, opts...)
+		}
+	})
+}
+
+// writeOutput calls write with stdout if path is empty. Otherwise, the output is
+// written to the file only once write succeeds, so a failure never truncates it.
+func writeOutput(path string, stdout io.Writer, write func(io.Writer) error) error {
+	if path == "" {
+		return write(stdout)
+	}
+
+	var buf bytes.Buffer
+
+	if err := write(&buf); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0o600)
+}
+
+func loadCatalog(path string) (*gerrors.Mapper, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return gerrors.LoadCatalog(f)
+}
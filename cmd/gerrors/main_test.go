@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testCatalog = "../gerrors-gen/testdata/errors.json"
+
+func TestRunDocs(t *testing.T) {
+	t.Parallel()
+
+	var stdout bytes.Buffer
+
+	if err := run([]string{"docs", "-catalog", testCatalog}, &stdout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(stdout.String(), `<a id="quota-exceeded"></a>`) {
+		t.Errorf("expected markdown docs on stdout, got:\n%s", stdout.String())
+	}
+
+	out := filepath.Join(t.TempDir(), "errors.html")
+
+	if err := run([]string{"docs", "-catalog", testCatalog, "-format", "html", "-out", out}, &stdout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(content), `<section id="quota-exceeded">`) {
+		t.Errorf("expected html docs in the output file, got:\n%s", content)
+	}
+}
+
+func TestRunDocsKeepsOutputOnError(t *testing.T) {
+	t.Parallel()
+
+	out := filepath.Join(t.TempDir(), "ERRORS.md")
+
+	if err := os.WriteFile(out, []byte("previous docs"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := [][]string{
+		{"docs", "-catalog", testCatalog, "-format", "pdf", "-out", out},
+		{"docs", "-catalog", "missing.json", "-out", out},
+	}
+
+	for _, args := range testCases {
+		if err := run(args, &bytes.Buffer{}); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+
+		content, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != "previous docs" {
+			t.Errorf("expected output file to be kept for %v, got:\n%s", args, content)
+		}
+	}
+}
+
+func TestRunSchema(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"openapi":    `"openapi": "3.1.0"`,
+		"jsonschema": `"$schema": "https://json-schema.org/draft/2020-12/schema"`,
+		"typescript": `QuotaExceeded = 101,`,
+	}
+
+	for command, expected := range testCases {
+		var stdout bytes.Buffer
+
+		if err := run([]string{command, "-catalog", testCatalog}, &stdout); err != nil {
+			t.Fatalf("unexpected error running %s: %v", command, err)
+		}
+
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("expected %s output to contain %s, got:\n%s", command, expected, stdout.String())
+		}
+	}
+}
+
+func TestRunSchemaAIP193(t *testing.T) {
+	t.Parallel()
+
+	catalog := filepath.Join(t.TempDir(), "errors.json")
+	content := `{"unknown_code": 1, "domain": "users.example.com", "errors": [
+		{"code": 1, "identifier": "unknown", "message": "unknown error"},
+		{"code": 100, "identifier": "user-not-found", "message": "user was not found",
+		 "message_template": "user {{.user_id}} was not found", "grpc_code": "NotFound"}
+	]}`
+
+	if err := os.WriteFile(catalog, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write catalog: %v", err)
+	}
+
+	var stdout bytes.Buffer
+
+	if err := run([]string{"typescript", "-catalog", catalog, "-aip193"}, &stdout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{`| "USER_NOT_FOUND"`, `"userId": string;`} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("expected output to contain %s, got:\n%s", expected, stdout.String())
+		}
+	}
+
+	noDomain := filepath.Join(t.TempDir(), "errors.json")
+
+	if err := os.WriteFile(noDomain, []byte(strings.Replace(content, `"domain": "users.example.com", `, "", 1)), 0o600); err != nil {
+		t.Fatalf("failed to write catalog: %v", err)
+	}
+
+	if err := run([]string{"openapi", "-catalog", noDomain, "-aip193"}, &stdout); err == nil {
+		t.Errorf("expected catalog without a domain to violate AIP-193")
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	t.Parallel()
+
+	testCases := [][]string{
+		nil,
+		{"unknown"},
+		{"docs"},
+		{"openapi"},
+	}
+
+	for _, args := range testCases {
+		if err := run(args, &bytes.Buffer{}); !errors.Is(err, errUsage) {
+			t.Errorf("expected usage error for %v, got %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package gerrors
+
+import (
+	"sort"
+)
+
+// CodeRange is an inclusive range of codes, e.g. {From: 1000, To: 1999}.
+type CodeRange struct {
+	From Code
+	To   Code
+}
+
+// chainLookuper tries its lookupers in order. Check [Chain].
+type chainLookuper struct {
+	lookupers []Lookuper
+	fallback  Lookuper
+}
+
+// rangeLookuper delegates ranges of codes to lookupers. Check [Ranges].
+type rangeLookuper struct {
+	ranges    []CodeRange
+	lookupers []Lookuper
+}
+
+// Chain combines lookupers, e.g. the package's default mapping and team-owned catalogs.
+// A code is translated by the first lookuper that has an explicit mapping for it.
+// If none of them has, the first lookuper's Lookup is used, so its fallback,
+// e.g. its unknown error code, applies.
+//
+// Lookupers that implement [TryLookuper] are asked using TryLookup. For other
+// lookupers, a [CoreError] with the same code as the requested one counts as a match.
+func Chain(lookupers ...Lookuper) TryLookuper {
+	c := &chainLookuper{
+		lookupers: lookupers,
+		fallback:  nil,
+	}
+
+	if len(lookupers) > 0 {
+		c.fallback = lookupers[0]
+	}
+
+	return c
+}
+
+// Overlay replaces or adds a few codes on top of the base lookuper, without
+// copying its mapping. Codes that are not overridden, and the fallback for
+// unknown codes, are translated by the base lookuper.
+//
+//	lookuper := gerrors.Overlay(
+//		gerrors.NewMapper(gerrors.Unknown, gerrors.GetDefaultMapping()),
+//		map[gerrors.Code]gerrors.CoreError{gerrors.NotFound: myNotFound},
+//	)
+func Overlay(base Lookuper, overrides map[Code]CoreError) TryLookuper {
+	return &chainLookuper{
+		lookupers: []Lookuper{NewMapper(Unknown, overrides), base},
+		fallback:  base,
+	}
+}
+
+// Ranges delegates blocks of codes to lookupers, e.g. 1000 to 1999 to the
+// billing team's catalog. A code is translated by the lookuper of the range
+// that contains it. If ranges overlap, the range with the lowest From wins.
+// Codes outside of every range are translated to a last resort [CoreError],
+// so Ranges is usually chained with a lookuper for the remaining codes:
+//
+//	lookuper := gerrors.Chain(
+//		gerrors.NewMapper(gerrors.Unknown, gerrors.GetDefaultMapping()),
+//		gerrors.Ranges(map[gerrors.CodeRange]gerrors.Lookuper{
+//			{From: 1000, To: 1999}: billing.NewMapper(),
+//			{From: 2000, To: 2999}: shipping.NewMapper(),
+//		}),
+//	)
+func Ranges(ranges map[CodeRange]Lookuper) TryLookuper {
+	r := &rangeLookuper{
+		ranges:    make([]CodeRange, 0, len(ranges)),
+		lookupers: make([]Lookuper, 0, len(ranges)),
+	}
+
+	for codeRange := range ranges {
+		r.ranges = append(r.ranges, codeRange)
+	}
+
+	sort.Slice(r.ranges, func(i, j int) bool {
+		if r.ranges[i].From != r.ranges[j].From {
+			return r.ranges[i].From < r.ranges[j].From
+		}
+
+		return r.ranges[i].To < r.ranges[j].To
+	})
+
+	for _, codeRange := range r.ranges {
+		r.lookupers = append(r.lookupers, ranges[codeRange])
+	}
+
+	return r
+}
+
+// Contains reports whether the code is within the range.
+func (r CodeRange) Contains(code Code) bool {
+	return code >= r.From && code <= r.To
+}
+
+// Lookup is part of [Lookuper] interface implementation.
+func (c *chainLookuper) Lookup(code Code) CoreError {
+	if core, ok := c.TryLookup(code); ok {
+		return core
+	}
+
+	if c.fallback == nil {
+		return lastResortCore(Unknown)
+	}
+
+	return c.fallback.Lookup(code)
+}
+
+// TryLookup is part of [TryLookuper] interface implementation.
+func (c *chainLookuper) TryLookup(code Code) (CoreError, bool) {
+	for _, l := range c.lookupers {
+		if core, ok := tryLookup(l, code); ok {
+			return core, true
+		}
+	}
+
+	return nil, false
+}
+
+// Lookup is part of [Lookuper] interface implementation.
+func (r *rangeLookuper) Lookup(code Code) CoreError {
+	if l := r.lookuper(code); l != nil {
+		return l.Lookup(code)
+	}
+
+	return lastResortCore(Unknown)
+}
+
+// TryLookup is part of [TryLookuper] interface implementation.
+func (r *rangeLookuper) TryLookup(code Code) (CoreError, bool) {
+	if l := r.lookuper(code); l != nil {
+		return tryLookup(l, code)
+	}
+
+	return nil, false
+}
+
+// lookuper returns the lookuper of the range that contains the code.
+func (r *rangeLookuper) lookuper(code Code) Lookuper {
+	for i, codeRange := range r.ranges {
+		if codeRange.Contains(code) {
+			return r.lookupers[i]
+		}
+	}
+
+	return nil
+}
+
+// tryLookup looks the code up without accepting a fallback to another code.
+func tryLookup(l Lookuper, code Code) (CoreError, bool) {
+	if tl, ok := l.(TryLookuper); ok {
+		return tl.TryLookup(code)
+	}
+
+	core := l.Lookup(code)
+	if core == nil || core.GetInternalCode() != code {
+		return nil, false
+	}
+
+	return core, true
+}
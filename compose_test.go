@@ -0,0 +1,237 @@
+package gerrors_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+)
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	defaults := gerrors.NewMapper(gerrors.Unknown, gerrors.GetDefaultMapping())
+	team := gerrors.NewMapper(gerrors.Unknown, map[gerrors.Code]gerrors.CoreError{
+		1001: composeCore{code: 1001, identifier: "invoice-not-found"},
+	})
+
+	testCases := []struct {
+		name               string
+		lookuper           gerrors.Lookuper
+		code               gerrors.Code
+		expectedCode       gerrors.Code
+		expectedIdentifier string
+		expectedMatch      bool
+	}{
+		{
+			name:               "first lookuper match",
+			lookuper:           gerrors.Chain(defaults, team),
+			code:               gerrors.NotFound,
+			expectedCode:       gerrors.NotFound,
+			expectedIdentifier: "not-found",
+			expectedMatch:      true,
+		},
+		{
+			name:               "second lookuper match",
+			lookuper:           gerrors.Chain(defaults, team),
+			code:               1001,
+			expectedCode:       1001,
+			expectedIdentifier: "invoice-not-found",
+			expectedMatch:      true,
+		},
+		{
+			name:               "plain lookuper match",
+			lookuper:           gerrors.Chain(defaults, staticLookuper{}),
+			code:               2000,
+			expectedCode:       2000,
+			expectedIdentifier: "static",
+			expectedMatch:      true,
+		},
+		{
+			name:               "plain lookuper fallback is not a match",
+			lookuper:           gerrors.Chain(staticLookuper{}, defaults),
+			code:               gerrors.NotFound,
+			expectedCode:       gerrors.NotFound,
+			expectedIdentifier: "not-found",
+			expectedMatch:      true,
+		},
+		{
+			name:               "no match falls back to first lookuper",
+			lookuper:           gerrors.Chain(defaults, team),
+			code:               3000,
+			expectedCode:       gerrors.Unknown,
+			expectedIdentifier: "unknown",
+			expectedMatch:      false,
+		},
+		{
+			name:               "empty chain",
+			lookuper:           gerrors.Chain(),
+			code:               gerrors.NotFound,
+			expectedCode:       gerrors.Unknown,
+			expectedIdentifier: "unknown",
+			expectedMatch:      false,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			core := tc.lookuper.Lookup(tc.code)
+
+			if core.GetInternalCode() != tc.expectedCode || core.GetIdentifier() != tc.expectedIdentifier {
+				t.Errorf("expected %d %s, got %d %s",
+					tc.expectedCode, tc.expectedIdentifier, core.GetInternalCode(), core.GetIdentifier())
+			}
+
+			tl, ok := tc.lookuper.(gerrors.TryLookuper)
+			if !ok {
+				t.Fatalf("expected chain to implement TryLookuper")
+			}
+
+			if _, match := tl.TryLookup(tc.code); match != tc.expectedMatch {
+				t.Errorf("expected match %v, got %v", tc.expectedMatch, match)
+			}
+		})
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	t.Parallel()
+
+	lookuper := gerrors.Overlay(
+		gerrors.NewMapper(gerrors.Unknown, gerrors.GetDefaultMapping()),
+		map[gerrors.Code]gerrors.CoreError{
+			gerrors.NotFound: composeCore{code: gerrors.NotFound, identifier: "not-found", message: "nothing here"},
+		},
+	)
+
+	if msg := lookuper.Lookup(gerrors.NotFound).GetDefaultMessage(); msg != "nothing here" {
+		t.Errorf("expected overridden message, got %s", msg)
+	}
+
+	if id := lookuper.Lookup(gerrors.InvalidArgument).GetIdentifier(); id != "invalid-argument" {
+		t.Errorf("expected base identifier, got %s", id)
+	}
+
+	if code := lookuper.Lookup(1000).GetInternalCode(); code != gerrors.Unknown {
+		t.Errorf("expected base fallback, got %d", code)
+	}
+
+	err := gerrors.NewFormatter(gerrors.WithLookuper(lookuper)).New(nil, gerrors.NotFound)
+
+	if !strings.Contains(err.Error(), "nothing here") {
+		t.Errorf("expected overridden message in error, got %s", err.Error())
+	}
+}
+
+func TestRanges(t *testing.T) {
+	t.Parallel()
+
+	billing := gerrors.NewMapper(1000, map[gerrors.Code]gerrors.CoreError{
+		1000: composeCore{code: 1000, identifier: "billing-unknown"},
+		1001: composeCore{code: 1001, identifier: "invoice-not-found"},
+	})
+	shipping := gerrors.NewMapper(2000, map[gerrors.Code]gerrors.CoreError{
+		2000: composeCore{code: 2000, identifier: "shipping-unknown"},
+	})
+
+	ranges := gerrors.Ranges(map[gerrors.CodeRange]gerrors.Lookuper{
+		{From: 1000, To: 1999}: billing,
+		{From: 2000, To: 2999}: shipping,
+	})
+
+	testCases := []struct {
+		name               string
+		code               gerrors.Code
+		expectedCode       gerrors.Code
+		expectedIdentifier string
+		expectedMatch      bool
+	}{
+		{
+			name:               "explicit match",
+			code:               1001,
+			expectedCode:       1001,
+			expectedIdentifier: "invoice-not-found",
+			expectedMatch:      true,
+		},
+		{
+			name:               "range fallback",
+			code:               2500,
+			expectedCode:       2000,
+			expectedIdentifier: "shipping-unknown",
+			expectedMatch:      false,
+		},
+		{
+			name:               "outside of ranges",
+			code:               gerrors.NotFound,
+			expectedCode:       gerrors.Unknown,
+			expectedIdentifier: "unknown",
+			expectedMatch:      false,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			core := ranges.Lookup(tc.code)
+
+			if core.GetInternalCode() != tc.expectedCode || core.GetIdentifier() != tc.expectedIdentifier {
+				t.Errorf("expected %d %s, got %d %s",
+					tc.expectedCode, tc.expectedIdentifier, core.GetInternalCode(), core.GetIdentifier())
+			}
+
+			if _, match := ranges.TryLookup(tc.code); match != tc.expectedMatch {
+				t.Errorf("expected match %v, got %v", tc.expectedMatch, match)
+			}
+		})
+	}
+
+	chained := gerrors.Chain(gerrors.NewMapper(gerrors.Unknown, gerrors.GetDefaultMapping()), ranges)
+
+	if id := chained.Lookup(gerrors.NotFound).GetIdentifier(); id != "not-found" {
+		t.Errorf("expected default identifier, got %s", id)
+	}
+
+	if id := chained.Lookup(1001).GetIdentifier(); id != "invoice-not-found" {
+		t.Errorf("expected billing identifier, got %s", id)
+	}
+}
+
+type composeCore struct {
+	code       gerrors.Code
+	identifier string
+	message    string
+}
+
+func (c composeCore) GetInternalCode() gerrors.Code {
+	return c.code
+}
+
+func (c composeCore) GetIdentifier() string {
+	return c.identifier
+}
+
+func (c composeCore) GetDefaultMessage() string {
+	if c.message == "" {
+		return c.identifier
+	}
+
+	return c.message
+}
+
+// staticLookuper translates every code above 1000 to itself, and falls back to 1000 otherwise.
+type staticLookuper struct{}
+
+func (staticLookuper) Lookup(code gerrors.Code) gerrors.CoreError {
+	if code < 1000 {
+		code = 1000
+	}
+
+	return composeCore{code: code, identifier: "static"}
+}
@@ -1,6 +1,18 @@
 package gerrors
 
-import "google.golang.org/grpc/codes"
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"slices"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ErrInvalidMapping is returned by [NewMapperE] whenever the mapping fails validation.
+var ErrInvalidMapping = errors.New("invalid error mapping")
 
 // Code is gerrors internal error type.
 // If a customized core call back function is used, customized error codes
@@ -34,12 +46,125 @@ type CoreGRPCError interface {
 	GetGRPCCode() codes.Code
 }
 
+// CoreHTTPError can provide support for HTTP responses.
+// If the provided error mapper implements this interface, the error can be
+// matched to an HTTP status code.
+type CoreHTTPError interface {
+	// GetHTTPStatus returns the HTTP status code that can be matched to
+	// an internal gerrors error code. e.g. 404
+	GetHTTPStatus() int
+}
+
+// CoreRetryableError can tell whether the operation that failed with the
+// error code can be retried by the caller.
+type CoreRetryableError interface {
+	// IsRetryable returns true if retrying the operation might succeed.
+	IsRetryable() bool
+}
+
+// CoreSeverityError can provide the severity of the error code.
+type CoreSeverityError interface {
+	// GetSeverity returns the severity of the error. e.g. "warning", "error"
+	GetSeverity() string
+}
+
+// CoreHelpError can provide a link to the documentation of the error code.
+// If the provided error mapper implements this interface, the link is attached
+// to gRPC errors as a Help detail.
+type CoreHelpError interface {
+	// GetHelpURL returns the URL of the error code's documentation.
+	GetHelpURL() string
+}
+
+// CoreDeprecatedError can mark an error code as deprecated.
+type CoreDeprecatedError interface {
+	// GetDeprecation returns a note explaining why the error code is deprecated
+	// and what should be used instead. It is empty if the code is not deprecated.
+	GetDeprecation() string
+}
+
+// CoreDomainError can provide the domain of the error code, e.g. "billing.example.com".
+// Codes are only unique within a domain, so errors are identified by their
+// (domain, code) and (domain, reason) pairs once they cross a service boundary.
+// If the provided error mapper implements this interface, its domain takes
+// precedence over the formatter's domain. Check [WithDomain] for more information.
+type CoreDomainError interface {
+	// GetDomain returns the logical grouping the error code belongs to,
+	// typically the DNS name of the service that generates it.
+	GetDomain() string
+}
+
+// CoreReasonError can provide an explicit reason for the error code, instead of the one
+// derived from its identifier. It is sent as the Reason of the [errdetails.ErrorInfo].
+// AIP-193 expects reasons to be UPPER_SNAKE_CASE, e.g. "USER_NOT_FOUND".
+type CoreReasonError interface {
+	// GetReason returns the reason of the error. If it is empty, the reason is
+	// derived from the identifier.
+	GetReason() string
+}
+
+// CoreMessageTemplate can provide a parameterized default message.
+// If the provided error mapper implements this interface, the default message
+// of the error is generated by filling in the template from the error's labels.
+// Missing labels are handled based on the formatter's missing value replacement
+// policy. Check [WithMissingValueReplacement] for more information.
+// GetDefaultMessage is still used whenever the template cannot be rendered.
+type CoreMessageTemplate interface {
+	// GetMessageTemplate returns a text/template string whose fields are
+	// the error's label keys. e.g. "user {{.user_id}} not found in {{.region}}"
+	GetMessageTemplate() string
+}
+
+// CoreLabelSchema can declare the labels of the error code: which ones are required,
+// which ones are allowed and what type their values have.
+// If the provided error mapper implements this interface, the labels of new errors
+// are checked against the schema, depending on the formatter's label validation mode,
+// and the schema is used by the generated documents. Check [WithLabelValidation].
+type CoreLabelSchema interface {
+	// GetLabelSchema returns the label schema of the error code, or nil if it has none.
+	GetLabelSchema() *LabelSchema
+}
+
 // Lookuper is an interface that shows how a mapper should be implemented.
 // Every mapper should have a lookup method to translate [Code] to [CoreError].
 type Lookuper interface {
 	Lookup(code Code) CoreError
 }
 
+// TryLookuper is a [Lookuper] that can tell whether a code has an explicit mapping.
+// It is used by lookuper combinators, e.g. [Chain], to find the lookuper that owns a code.
+type TryLookuper interface {
+	Lookuper
+
+	// TryLookup returns the [CoreError] of the code, and false if the code has
+	// no explicit mapping. Unlike Lookup, it never falls back to another code.
+	TryLookup(code Code) (CoreError, bool)
+}
+
+// EnumerableLookuper is a [Lookuper] that can list all of its codes and
+// translate the information on the wire back to [CoreError].
+// It is used by tools that need every error code, e.g. [WriteDocs], and by
+// clients that parse errors, e.g. from their identifier or reason.
+type EnumerableLookuper interface {
+	Lookuper
+
+	// Codes returns all the codes that the lookuper can translate, sorted.
+	Codes() []Code
+
+	// All returns an iterator over the codes and their [CoreError], sorted by code.
+	All() iter.Seq2[Code, CoreError]
+
+	// ByIdentifier returns the [CoreError] with the given identifier.
+	ByIdentifier(identifier string) (CoreError, bool)
+
+	// ByReason returns the [CoreError] with the given reason of the error details.
+	// e.g. "NOT-FOUND"
+	ByReason(reason string) (CoreError, bool)
+
+	// ByGRPCCode returns all the [CoreError] with the given gRPC code, sorted by code.
+	ByGRPCCode(code codes.Code) []CoreError
+}
+
 // gerrorCore is the default implementation of CoreError and CoreGRPCError.
 type gerrorCore struct {
 	internalCode   Code
@@ -55,6 +180,12 @@ type gerrorCore struct {
 type Mapper struct {
 	mapping      map[Code]CoreError
 	unknownError Code
+
+	// codes and the maps below are reverse indexes of mapping.
+	codes        []Code
+	byIdentifier map[string]CoreError
+	byReason     map[string]CoreError
+	byGRPCCode   map[codes.Code][]CoreError
 }
 
 const (
@@ -125,31 +256,187 @@ const (
 	ExternalRequest
 )
 
+// NewMapperE is the same as [NewMapper], but it validates the mapping first.
+// A valid mapping has an entry for unknownErrorCode, no nil entries, entries whose
+// [CoreError.GetInternalCode] matches their code, and unique identifiers.
+// Every violation is reported, wrapping [ErrInvalidMapping].
+func NewMapperE(unknownErrorCode Code, mapping map[Code]CoreError) (*Mapper, error) {
+	var errs []error
+
+	if mapping[unknownErrorCode] == nil {
+		errs = append(errs, fmt.Errorf("%w: unknown code %d has no entry", ErrInvalidMapping, unknownErrorCode))
+	}
+
+	identifiers := make(map[string]Code, len(mapping))
+	codesList := make([]Code, 0, len(mapping))
+
+	for code := range mapping {
+		codesList = append(codesList, code)
+	}
+
+	sort.Slice(codesList, func(i, j int) bool {
+		return codesList[i] < codesList[j]
+	})
+
+	for _, code := range codesList {
+		core := mapping[code]
+		if core == nil {
+			if code != unknownErrorCode {
+				errs = append(errs, fmt.Errorf("%w: code %d has a nil entry", ErrInvalidMapping, code))
+			}
+
+			continue
+		}
+
+		if core.GetInternalCode() != code {
+			errs = append(errs, fmt.Errorf("%w: code %d is mapped to an entry of code %d",
+				ErrInvalidMapping, code, core.GetInternalCode()))
+		}
+
+		if other, ok := identifiers[core.GetIdentifier()]; ok {
+			errs = append(errs, fmt.Errorf("%w: codes %d and %d have the same identifier %q",
+				ErrInvalidMapping, other, code, core.GetIdentifier()))
+		}
+
+		identifiers[core.GetIdentifier()] = code
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return NewMapper(unknownErrorCode, mapping), nil
+}
+
+// lastResortCore returns the [CoreError] that is used whenever no [CoreError]
+// is available for a code, not even for the unknown code, instead of nil.
+func lastResortCore(code Code) CoreError {
+	return &gerrorCore{
+		internalCode:   code,
+		identifier:     "unknown",
+		defaultMessage: "no information is available for this type of error",
+		grpcCode:       codes.Unknown,
+	}
+}
+
 // NewMapper initiates the Mapper with all available one-to-one mapping
 // information from an error code to error details.
+// The mapping is not validated. Use [NewMapperE] to catch mistakes, e.g. a missing
+// entry for the unknown error code, when the mapper is created.
 // mapping is a map that maps the [Code] to [CoreError]. This can be customized
 // based on your needs.
 // unknownErrorCode will be used whenever mapper cannot translate a [Code]
 // to [CoreError] and this unknown code is used as a fallback.
+// The mapping is indexed for reverse lookups, so it should not be modified afterwards.
 func NewMapper(unknownErrorCode Code, mapping map[Code]CoreError) *Mapper {
-	return &Mapper{
+	m := &Mapper{
 		mapping:      mapping,
 		unknownError: unknownErrorCode,
+		codes:        make([]Code, 0, len(mapping)),
+		byIdentifier: make(map[string]CoreError, len(mapping)),
+		byReason:     make(map[string]CoreError, len(mapping)),
+		byGRPCCode:   make(map[codes.Code][]CoreError),
 	}
+
+	for code, core := range mapping {
+		if core != nil {
+			m.codes = append(m.codes, code)
+		}
+	}
+
+	sort.Slice(m.codes, func(i, j int) bool {
+		return m.codes[i] < m.codes[j]
+	})
+
+	// Codes are indexed in order, so the lowest code wins whenever
+	// two codes share an identifier or a reason.
+	for _, code := range m.codes {
+		core := mapping[code]
+
+		if _, ok := m.byIdentifier[core.GetIdentifier()]; !ok {
+			m.byIdentifier[core.GetIdentifier()] = core
+		}
+
+		for _, reason := range reasonsOf(core) {
+			if _, ok := m.byReason[reason]; !ok {
+				m.byReason[reason] = core
+			}
+		}
+
+		if coreg, ok := core.(CoreGRPCError); ok {
+			m.byGRPCCode[coreg.GetGRPCCode()] = append(m.byGRPCCode[coreg.GetGRPCCode()], core)
+		}
+	}
+
+	return m
 }
 
 // Lookup helps [Mapper] to implement [Lookuper] interface that can be passed to
 // [Formatter] and acts as the translator for translating [Code] to [CoreError].
+// If the code has no mapping, the unknown error code is used instead, and if that
+// has no mapping either, a built-in last resort [CoreError] is returned. Lookup never
+// returns nil.
 func (m *Mapper) Lookup(code Code) CoreError {
-	var selectedInfo CoreError
+	if rec := m.mapping[code]; rec != nil {
+		return rec
+	}
 
-	if rec, ok := m.mapping[code]; ok {
-		selectedInfo = rec
-	} else {
-		selectedInfo = m.mapping[m.unknownError]
+	if rec := m.mapping[m.unknownError]; rec != nil {
+		return rec
 	}
 
-	return selectedInfo
+	return lastResortCore(m.unknownError)
+}
+
+// TryLookup helps [Mapper] to implement [TryLookuper] interface.
+func (m *Mapper) TryLookup(code Code) (CoreError, bool) {
+	rec := m.mapping[code]
+
+	return rec, rec != nil
+}
+
+// Codes helps [Mapper] to implement [EnumerableLookuper] interface.
+// It returns all the codes of the mapping, sorted.
+func (m *Mapper) Codes() []Code {
+	return slices.Clone(m.codes)
+}
+
+// All helps [Mapper] to implement [EnumerableLookuper] interface.
+// It iterates over the codes of the mapping and their [CoreError], sorted by code.
+//
+//	for code, core := range mapper.All() {
+//		fmt.Println(code, core.GetIdentifier())
+//	}
+func (m *Mapper) All() iter.Seq2[Code, CoreError] {
+	return func(yield func(Code, CoreError) bool) {
+		for _, code := range m.codes {
+			if !yield(code, m.mapping[code]) {
+				return
+			}
+		}
+	}
+}
+
+// ByIdentifier helps [Mapper] to implement [EnumerableLookuper] interface.
+// Unlike [Mapper.Lookup], it does not fall back to the unknown error.
+func (m *Mapper) ByIdentifier(identifier string) (CoreError, bool) {
+	core, ok := m.byIdentifier[identifier]
+
+	return core, ok
+}
+
+// ByReason helps [Mapper] to implement [EnumerableLookuper] interface.
+// Unlike [Mapper.Lookup], it does not fall back to the unknown error.
+func (m *Mapper) ByReason(reason string) (CoreError, bool) {
+	core, ok := m.byReason[reason]
+
+	return core, ok
+}
+
+// ByGRPCCode helps [Mapper] to implement [EnumerableLookuper] interface.
+// Only the codes whose [CoreError] implements [CoreGRPCError] are matched.
+func (m *Mapper) ByGRPCCode(code codes.Code) []CoreError {
+	return slices.Clone(m.byGRPCCode[code])
 }
 
 // GetInternalCode is part of CoreError interface implementation.
@@ -176,6 +463,45 @@ func (g *gerrorCore) GetGRPCCode() codes.Code {
 	return g.grpcCode
 }
 
+// GetHTTPStatus is part of CoreHTTPError interface implementation.
+// It returns the HTTP status that matches the GRPC code of the error. e.g. 404 for codes.NotFound.
+func (g *gerrorCore) GetHTTPStatus() int {
+	return httpStatusFromGRPC(g.grpcCode)
+}
+
+// httpStatusFromGRPC returns the HTTP status code that matches the gRPC code
+// based on the mapping of google.rpc.Code.
+func httpStatusFromGRPC(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // nolint: mnd
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.Unknown, codes.Internal, codes.DataLoss:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // GetDefaultMapping returns a map that contains translation between package's
 // default error codes to detailed information. These information can be customized.
 // Check [Formatter] and [WithLookuper] for more information.
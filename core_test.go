@@ -1,6 +1,7 @@
 package gerrors_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/seinshah/gerrors"
@@ -63,3 +64,162 @@ func TestDefaultCoreCallback(t *testing.T) {
 		})
 	}
 }
+
+func TestMapperEnumerable(t *testing.T) {
+	t.Parallel()
+
+	var mapper gerrors.EnumerableLookuper = gerrors.NewMapper(gerrors.Unknown, gerrors.GetDefaultMapping())
+
+	codesList := mapper.Codes()
+	if len(codesList) != len(gerrors.GetDefaultMapping()) || codesList[0] != gerrors.Unknown {
+		t.Fatalf("expected all codes sorted, got %v", codesList)
+	}
+
+	index := 0
+
+	for code, core := range mapper.All() {
+		if code != codesList[index] || core.GetInternalCode() != code {
+			t.Errorf("expected code %d at %d, got %d", codesList[index], index, code)
+		}
+
+		index++
+	}
+
+	if index != len(codesList) {
+		t.Errorf("expected All to iterate over %d codes, got %d", len(codesList), index)
+	}
+
+	for range mapper.All() {
+		break
+	}
+
+	if core, ok := mapper.ByIdentifier("not-found"); !ok || core.GetInternalCode() != gerrors.NotFound {
+		t.Errorf("expected not-found identifier to be found")
+	}
+
+	if core, ok := mapper.ByReason("INVALID-ARGUMENT"); !ok || core.GetInternalCode() != gerrors.InvalidArgument {
+		t.Errorf("expected INVALID-ARGUMENT reason to be found")
+	}
+
+	if _, ok := mapper.ByIdentifier("missing"); ok {
+		t.Errorf("expected missing identifier not to fall back to unknown")
+	}
+
+	internal := mapper.ByGRPCCode(codes.Internal)
+
+	expected := []gerrors.Code{gerrors.Marshal, gerrors.Storage, gerrors.Internal, gerrors.ExternalRequest}
+	if len(internal) != len(expected) {
+		t.Fatalf("expected %d internal codes, got %d", len(expected), len(internal))
+	}
+
+	for i, core := range internal {
+		if core.GetInternalCode() != expected[i] {
+			t.Errorf("expected internal code %d at %d, got %d", expected[i], i, core.GetInternalCode())
+		}
+	}
+
+	if len(mapper.ByGRPCCode(codes.DataLoss)) != 0 {
+		t.Errorf("expected no codes for DataLoss")
+	}
+}
+
+func TestNewMapperE(t *testing.T) {
+	t.Parallel()
+
+	mapping := gerrors.GetDefaultMapping()
+
+	testCases := []struct {
+		name        string
+		unknownCode gerrors.Code
+		mapping     map[gerrors.Code]gerrors.CoreError
+		expectedErr bool
+	}{
+		{
+			name:        "valid mapping",
+			unknownCode: gerrors.Unknown,
+			mapping:     mapping,
+			expectedErr: false,
+		},
+		{
+			name:        "missing unknown code",
+			unknownCode: gerrors.Code(1000),
+			mapping:     mapping,
+			expectedErr: true,
+		},
+		{
+			name:        "nil entry",
+			unknownCode: gerrors.Unknown,
+			mapping:     map[gerrors.Code]gerrors.CoreError{gerrors.Unknown: mapping[gerrors.Unknown], gerrors.NotFound: nil},
+			expectedErr: true,
+		},
+		{
+			name:        "mismatching code",
+			unknownCode: gerrors.Unknown,
+			mapping:     map[gerrors.Code]gerrors.CoreError{gerrors.Unknown: mapping[gerrors.Unknown], 50: mapping[gerrors.NotFound]},
+			expectedErr: true,
+		},
+		{
+			name:        "duplicate identifier",
+			unknownCode: gerrors.Unknown,
+			mapping:     map[gerrors.Code]gerrors.CoreError{gerrors.Unknown: mapping[gerrors.Unknown], 50: duplicateCore{}},
+			expectedErr: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mapper, err := gerrors.NewMapperE(tc.unknownCode, tc.mapping)
+
+			if tc.expectedErr {
+				if !errors.Is(err, gerrors.ErrInvalidMapping) || mapper != nil {
+					t.Errorf("expected invalid mapping error, got %v", err)
+				}
+
+				return
+			}
+
+			if err != nil || mapper == nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestMapperLastResort(t *testing.T) {
+	t.Parallel()
+
+	mapper := gerrors.NewMapper(gerrors.Code(1000), map[gerrors.Code]gerrors.CoreError{})
+
+	core := mapper.Lookup(gerrors.NotFound)
+	if core == nil {
+		t.Fatalf("expected last resort core error instead of nil")
+	}
+
+	if core.GetInternalCode() != gerrors.Code(1000) || core.GetIdentifier() != "unknown" {
+		t.Errorf("unexpected last resort core error: %d %s", core.GetInternalCode(), core.GetIdentifier())
+	}
+
+	err := gerrors.NewFormatter(gerrors.WithLookuper(mapper)).New(nil, gerrors.NotFound)
+
+	if err.Metadata()[gerrors.MetadataRequestedCode] != "2" {
+		t.Errorf("expected requested code label, got %v", err.Metadata())
+	}
+}
+
+type duplicateCore struct{}
+
+func (duplicateCore) GetInternalCode() gerrors.Code {
+	return 50
+}
+
+func (duplicateCore) GetIdentifier() string {
+	return "unknown"
+}
+
+func (duplicateCore) GetDefaultMessage() string {
+	return "duplicate"
+}
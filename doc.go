@@ -21,7 +21,41 @@
 //
 // Formatter uses text/template to generate the final error message. It uses a default template, which can
 // be customized using WithTemplate helper function. More information on the available variables have been
-// explained in helper's documentation.
+// explained in helper's documentation. Templates can use a set of built-in functions, custom functions
+// registered using WithTemplateFuncs, and named sub-templates that are rendered by GeneralError.Render.
+// The template is the default Renderer of the formatter. JSONRenderer and LogfmtRenderer can be used
+// instead through WithRenderer, whenever the error message is parsed by log pipelines.
+//
+// # Wrapping
+//
+// While an error bubbles up, Wrap and Annotate can be used to record the operations it passed through
+// and to add more labels to it, without losing its code and the labels it already has.
+// The chain of operations is available as {{.Operation}} in the template and as the _operation label.
+//
+// # Labels
+//
+// Labels never override system labels such as _error_code. WithReservedKeyPolicy decides whether such labels
+// are rejected, renamed or ignored, and WithLabelConflictPolicy decides what happens when an error label has
+// the same key as a formatter label. WithSystemKey renames or disables individual system keys.
+//
+// # Metadata budget
+//
+// WithMetadataBudget limits the size of the metadata that is sent through gRPC and rendered by renderers,
+// by truncating, hashing or dropping labels and listing them in the _truncated label. Loggers still receive
+// the full values.
+//
+// # Label schema
+//
+// A CoreError implementing CoreLabelSchema, or a catalog entry with labels, declares which labels its errors
+// require, which ones they may have and of what type, e.g. an integer limit. WithLabelValidation checks new errors
+// against the schema, logging the violations or panicking during development. The same schema documents the
+// labels in WriteDocs, WriteOpenAPI and WriteTypeScript, and types the constructors generated by gerrors-gen.
+//
+// # Redaction
+//
+// WithRedaction scrubs secrets and personal data from labels, the original error and rendered messages before
+// the error is logged or sent anywhere. Rules match sensitive label keys or detect sensitive values, such as
+// emails, credit card numbers or tokens, and mask, hash or drop them. Check DefaultRedactionRules.
 //
 // # gRPC
 //
@@ -30,6 +64,44 @@
 // package without much customization. However, they can be easily customized using WithCustomCoreCallback
 // helper function.
 //
+// Codes are only unique within a domain. WithDomain, or a CoreError implementing CoreDomainError, sets the
+// domain of the ErrorInfo and the _domain label, so IsCode and IsReason can match errors by their
+// (domain, code) and (domain, reason) pairs, even after they have crossed a gRPC boundary.
+// GeneralError.QualifiedCode names the same pair in metrics.
+//
+// WithAIP193 turns on a strict mode that derives UPPER_SNAKE_CASE reasons, unless a CoreError implements
+// CoreReasonError, converts metadata keys to lowerCamelCase (see WithKeyCase), and reports violations
+// through NewFormatterE. Catalog.ValidateAIP193 checks a catalog against the same rules.
+//
+// # Catalog
+//
+// Instead of implementing CoreError in Go, error codes can be declared in a JSON catalog with their
+// identifier, default message, gRPC code, HTTP status, retryability, severity, help URL and deprecation.
+// LoadCatalog validates the catalog and returns a Mapper that can be passed to WithLookuper.
+// Besides Lookup, a Mapper implements EnumerableLookuper, which lists its codes and finds them by
+// identifier, reason or gRPC code. Similarly, MapperFromEnum builds a Mapper from a protobuf enum,
+// such as an AIP-193 ErrorReason enum.
+// WriteDocs renders a Markdown or HTML reference of every code of a Mapper, with stable anchors that
+// help URLs can point at. The same reference is available from the command line using "gerrors docs".
+// WriteOpenAPI and WriteJSONSchema describe the AIP-193 JSON error body of the codes as OpenAPI 3.1
+// schemas and responses, or as a standalone JSON Schema. WriteTypeScript generates a TypeScript module
+// with the codes, identifiers, reasons and labels of the errors, and type guards to parse error bodies.
+//
+// Lookupers can be combined: Chain tries several lookupers until one has an explicit mapping for a code,
+// Overlay replaces a few codes of a base lookuper, and Ranges delegates blocks of codes to team-owned catalogs.
+// A Registry is a concurrency-safe lookuper whose codes are registered at runtime, e.g. from init functions,
+// and which rejects colliding codes, identifiers and reasons, naming the package that registered them first.
+// A ReloadableLookuper polls a catalog source and atomically swaps in new valid versions of the catalog,
+// so messages, HTTP statuses and help URLs can be fixed without redeploying.
+//
+// # Localization
+//
+// A Localizer holds message catalogs per locale and code, which can be loaded from JSON files.
+// Once attached to a formatter using WithLocalizer, errors can be localized by LocalizedMessage based on
+// an Accept-Language value, and GrpcErrorContext attaches the localized message to the gRPC error.
+// For HTTP, LocaleHandler stores the Accept-Language header in the request's context, and
+// GeneralError.RenderWithContext renders the error with the localized message, e.g. using JSONRenderer.
+//
 // [Google's AIP 193]: https://google.aip.dev/193
 // [error details]: https://github.com/googleapis/googleapis/blob/master/google/rpc/error_details.proto#L111
 package gerrors
@@ -62,7 +62,8 @@ func ExampleNewFormatter_withOptions() {
 		// Any error we create on this formatter uses this lookuper.
 		// Due to our implementation here, since the unknown error code is
 		// 100 and no other code mapping has defined, any error you pass
-		// will be translated to the information of error code 100.
+		// will be translated to the information of error code 100, and
+		// the code that was originally requested is kept in _requested_code label.
 		gerrors.WithLookuper(
 			gerrors.NewMapper(gerrors.Code(100), map[gerrors.Code]gerrors.CoreError{
 				gerrors.Code(100): CustomCoreError{},
@@ -81,7 +82,7 @@ func ExampleNewFormatter_withOptions() {
 	err := f.New(errors.New("error"), gerrors.Unknown, "key", "value")
 	fmt.Println(err.Error())
 
-	// Output: custom: custom(100) - custom core error - 13 - map[_default_message:custom core error _error_code:100 _identifier:custom _original_error:error always:true key:value]
+	// Output: custom: custom(100) - custom core error - 13 - map[_default_message:custom core error _error_code:100 _identifier:custom _original_error:error _requested_code:1 always:true key:value]
 }
 
 func ExampleFormatter_Clone() {
@@ -101,7 +102,7 @@ func ExampleFormatter_Clone() {
 	err := f2.New(errors.New("error"), gerrors.Unknown, "key", "value")
 	fmt.Println(err.Error())
 
-	// Output: map[_default_message:custom core error _error_code:100 _identifier:custom _original_error:error key:value new:true override:cloned remains:yes]
+	// Output: map[_default_message:custom core error _error_code:100 _identifier:custom _original_error:error _requested_code:1 key:value new:true override:cloned remains:yes]
 }
 
 func (CustomCoreError) GetGRPCCode() codes.Code {
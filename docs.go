@@ -0,0 +1,394 @@
+package gerrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// DocsFormat is the output format of [WriteDocs].
+type DocsFormat string
+
+const (
+	// DocsMarkdown renders the error reference as Markdown.
+	DocsMarkdown DocsFormat = "markdown"
+
+	// DocsHTML renders the error reference as a standalone HTML page.
+	DocsHTML DocsFormat = "html"
+)
+
+// errUnknownDocsFormat is returned when [WriteDocs] is called with an unsupported format.
+var errUnknownDocsFormat = errors.New("unknown docs format")
+
+// errDocsAnchorCollision is returned when two identifiers have the same docs anchor.
+var errDocsAnchorCollision = errors.New("identifiers have the same docs anchor")
+
+// DocsOption customizes the error reference generated by [WriteDocs].
+type DocsOption func(*docsConfig)
+
+type docsConfig struct {
+	title     string
+	formatter *Formatter
+}
+
+// docsPage is the data passed to the docs templates.
+type docsPage struct {
+	Title   string
+	Entries []docsEntry
+}
+
+// docsEntry is the documentation of a single error code.
+type docsEntry struct {
+	Anchor          string
+	Code            Code
+	Identifier      string
+	Domain          string
+	Message         string
+	MessageTemplate string
+	GRPCCode        string
+	HTTPStatus      int
+	Retryable       bool
+	Severity        string
+	HelpURL         string
+	Deprecation     string
+	Labels          []LabelSpec
+	GRPCExample     string
+	JSONExample     string
+}
+
+// WithDocsTitle sets the title of the error reference. It defaults to "Error reference".
+func WithDocsTitle(title string) DocsOption {
+	return func(c *docsConfig) {
+		c.title = title
+	}
+}
+
+// WithDocsFormatter sets the formatter that is used to build the example payloads,
+// so that they reflect its template, labels and value encoders.
+// By default, a formatter with the documented lookuper is used.
+func WithDocsFormatter(f *Formatter) DocsOption {
+	return func(c *docsConfig) {
+		c.formatter = f
+	}
+}
+
+// DocsAnchor returns the anchor of an error identifier in the reference
+// generated by [WriteDocs]. Anchors only depend on the identifier, so they are
+// stable and can be used in help URLs. e.g. "User not found" becomes "user-not-found".
+//
+//	HelpURL: "https://example.com/errors#" + gerrors.DocsAnchor("user-not-found")
+func DocsAnchor(identifier string) string {
+	return strings.Join(IdentifierWords(identifier), "-")
+}
+
+// IdentifierWords splits an error identifier into its lower-cased words, which are separated
+// by any character other than letters and digits. e.g. "User_not-found" becomes "user", "not"
+// and "found". Anchors of [WriteDocs], schema names of [WriteOpenAPI] and names generated by
+// gerrors-gen are all made of these words, so they agree on how an identifier is split.
+func IdentifierWords(identifier string) []string {
+	return strings.FieldsFunc(strings.ToLower(identifier), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// WriteDocs writes a reference of all the error codes of the lookuper in the given format.
+// For every code, it documents its anchor, identifier, domain, gRPC code and HTTP status,
+// default message, retryability, deprecation, labels and example gRPC and JSON payloads.
+// Labels are the ones referenced by the message template and the ones declared by
+// the label schema of the code. Check [CoreLabelSchema].
+// The optional Core interfaces are used whenever the code's [CoreError] implements them.
+// A [Catalog] can be documented using [Catalog.Mapper].
+// An error is returned without writing anything if the format is unknown, or if two
+// identifiers have the same anchor, e.g. "user not found" and "user-not-found".
+func WriteDocs(w io.Writer, l EnumerableLookuper, format DocsFormat, opts ...DocsOption) error {
+	var docsTemplate interface {
+		Execute(w io.Writer, data any) error
+	}
+
+	switch format {
+	case DocsMarkdown:
+		docsTemplate = markdownDocsTemplate
+	case DocsHTML:
+		docsTemplate = htmlDocsTemplate
+	default:
+		return fmt.Errorf("%w: %q", errUnknownDocsFormat, format)
+	}
+
+	config := &docsConfig{
+		title:     "Error reference",
+		formatter: nil,
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if config.formatter == nil {
+		config.formatter = NewFormatter(WithLookuper(l))
+	}
+
+	page := docsPage{Title: config.title, Entries: nil}
+	anchors := make(map[string]string)
+
+	for _, core := range l.All() {
+		entry, err := newDocsEntry(config.formatter.Clone(), l, core)
+		if err != nil {
+			return err
+		}
+
+		if other, ok := anchors[entry.Anchor]; ok {
+			return fmt.Errorf("%w: %q and %q are both anchored at #%s",
+				errDocsAnchorCollision, other, entry.Identifier, entry.Anchor)
+		}
+
+		anchors[entry.Anchor] = entry.Identifier
+		page.Entries = append(page.Entries, entry)
+	}
+
+	return docsTemplate.Execute(w, page)
+}
+
+func newDocsEntry(f *Formatter, l Lookuper, core CoreError) (docsEntry, error) {
+	f.coreDataLookup = l
+
+	entry := docsEntry{
+		Anchor:          DocsAnchor(core.GetIdentifier()),
+		Code:            core.GetInternalCode(),
+		Identifier:      core.GetIdentifier(),
+		Domain:          f.domainOf(core),
+		Message:         core.GetDefaultMessage(),
+		MessageTemplate: "",
+		GRPCCode:        "",
+		HTTPStatus:      0,
+		Retryable:       false,
+		Severity:        "",
+		HelpURL:         "",
+		Deprecation:     "",
+		Labels:          labelSpecsOf(core),
+		GRPCExample:     "",
+		JSONExample:     "",
+	}
+
+	if corem, ok := core.(CoreMessageTemplate); ok {
+		entry.MessageTemplate = corem.GetMessageTemplate()
+	}
+
+	if coreg, ok := core.(CoreGRPCError); ok {
+		entry.GRPCCode = coreg.GetGRPCCode().String()
+		entry.HTTPStatus = httpStatusFromGRPC(coreg.GetGRPCCode())
+	}
+
+	if coreh, ok := core.(CoreHTTPError); ok {
+		entry.HTTPStatus = coreh.GetHTTPStatus()
+	}
+
+	if corer, ok := core.(CoreRetryableError); ok {
+		entry.Retryable = corer.IsRetryable()
+	}
+
+	if cores, ok := core.(CoreSeverityError); ok {
+		entry.Severity = cores.GetSeverity()
+	}
+
+	if coreh, ok := core.(CoreHelpError); ok {
+		entry.HelpURL = coreh.GetHelpURL()
+	}
+
+	if cored, ok := core.(CoreDeprecatedError); ok {
+		entry.Deprecation = cored.GetDeprecation()
+	}
+
+	// The example error is created without logging it.
+	ge := f.createError(nil, entry.Code)
+	ge.addLabels(exampleLabels(entry.Labels))
+
+	grpcExample, err := protojson.Marshal(status.Convert(GrpcError(ge)).Proto())
+	if err != nil {
+		return entry, err
+	}
+
+	view := ge.View()
+	view.Stack = ""
+
+	jsonExample, err := JSONRenderer{}.Render(view)
+	if err != nil {
+		return entry, err
+	}
+
+	if entry.GRPCExample, err = indentJSON(grpcExample); err != nil {
+		return entry, err
+	}
+
+	if entry.JSONExample, err = indentJSON([]byte(jsonExample)); err != nil {
+		return entry, err
+	}
+
+	return entry, nil
+}
+
+// indentJSON indents the JSON document, which also normalizes its whitespaces.
+func indentJSON(data []byte) (string, error) {
+	var buf bytes.Buffer
+
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// markdownCell escapes the pipes and line breaks that would end a Markdown table cell or row.
+var markdownCell = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+// markdownCodeCell is like markdownCell for the cells that are rendered as code spans,
+// in which line breaks cannot be rendered, so they are replaced by spaces.
+var markdownCodeCell = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ", "\r", " ")
+
+var docsFuncs = map[string]any{
+	"mdcell":     markdownCell.Replace,
+	"mdcodecell": markdownCodeCell.Replace,
+	"yesno": func(b bool) string {
+		if b {
+			return "yes"
+		}
+
+		return "no"
+	},
+	"labeltype": func(t LabelType) string {
+		if t == LabelTypeAny {
+			return "any"
+		}
+
+		return string(t)
+	},
+}
+
+var markdownDocsTemplate = template.Must(template.New("markdown").Funcs(docsFuncs).Parse(`# {{.Title}}
+{{range .Entries}}
+- [{{.Identifier}}](#{{.Anchor}}) ({{.Code}})
+{{- end}}
+{{range .Entries}}
+<a id="{{.Anchor}}"></a>
+
+## {{.Identifier}}
+{{if .Deprecation}}
+> **Deprecated:** {{.Deprecation}}
+{{end}}
+{{.Message}}
+
+| Property | Value |
+| --- | --- |
+| Code | ` + "`{{.Code}}`" + ` |
+| Identifier | ` + "`{{mdcodecell .Identifier}}`" + ` |
+{{- if .Domain}}
+| Domain | ` + "`{{.Domain}}`" + ` |
+{{- end}}
+{{- if .GRPCCode}}
+| gRPC code | ` + "`{{.GRPCCode}}`" + ` |
+{{- end}}
+{{- if .HTTPStatus}}
+| HTTP status | ` + "`{{.HTTPStatus}}`" + ` |
+{{- end}}
+| Retryable | {{yesno .Retryable}} |
+{{- if .Severity}}
+| Severity | {{mdcell .Severity}} |
+{{- end}}
+{{- if .MessageTemplate}}
+| Message template | ` + "`{{mdcodecell .MessageTemplate}}`" + ` |
+{{- end}}
+{{- if .HelpURL}}
+| Help | <{{.HelpURL}}> |
+{{- end}}
+{{- if .Labels}}
+
+| Label | Type | Required | Description |
+| --- | --- | --- | --- |
+{{- range .Labels}}
+| ` + "`{{.Key}}`" + ` | {{labeltype .Type}} | {{yesno .Required}} | {{mdcell .Description}} |
+{{- end}}
+{{- end}}
+
+Example gRPC status:
+
+` + "```json" + `
+{{.GRPCExample}}
+` + "```" + `
+
+Example JSON:
+
+` + "```json" + `
+{{.JSONExample}}
+` + "```" + `
+{{end -}}
+`))
+
+var htmlDocsTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(docsFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<ul>
+{{- range .Entries}}
+<li><a href="#{{.Anchor}}">{{.Identifier}}</a> ({{.Code}})</li>
+{{- end}}
+</ul>
+{{- range .Entries}}
+<section id="{{.Anchor}}">
+<h2>{{.Identifier}}</h2>
+{{- if .Deprecation}}
+<p><strong>Deprecated:</strong> {{.Deprecation}}</p>
+{{- end}}
+<p>{{.Message}}</p>
+<table>
+<tr><th>Code</th><td><code>{{.Code}}</code></td></tr>
+<tr><th>Identifier</th><td><code>{{.Identifier}}</code></td></tr>
+{{- if .Domain}}
+<tr><th>Domain</th><td><code>{{.Domain}}</code></td></tr>
+{{- end}}
+{{- if .GRPCCode}}
+<tr><th>gRPC code</th><td><code>{{.GRPCCode}}</code></td></tr>
+{{- end}}
+{{- if .HTTPStatus}}
+<tr><th>HTTP status</th><td><code>{{.HTTPStatus}}</code></td></tr>
+{{- end}}
+<tr><th>Retryable</th><td>{{yesno .Retryable}}</td></tr>
+{{- if .Severity}}
+<tr><th>Severity</th><td>{{.Severity}}</td></tr>
+{{- end}}
+{{- if .MessageTemplate}}
+<tr><th>Message template</th><td><code>{{.MessageTemplate}}</code></td></tr>
+{{- end}}
+{{- if .HelpURL}}
+<tr><th>Help</th><td><a href="{{.HelpURL}}">{{.HelpURL}}</a></td></tr>
+{{- end}}
+</table>
+{{- if .Labels}}
+<h3>Labels</h3>
+<table>
+<tr><th>Label</th><th>Type</th><th>Required</th><th>Description</th></tr>
+{{- range .Labels}}
+<tr><td><code>{{.Key}}</code></td><td>{{labeltype .Type}}</td><td>{{yesno .Required}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<h3>Example gRPC status</h3>
+<pre><code>{{.GRPCExample}}</code></pre>
+<h3>Example JSON</h3>
+<pre><code>{{.JSONExample}}</code></pre>
+</section>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,197 @@
+package gerrors_test
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+)
+
+func TestDocsAnchor(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"user-not-found":  "user-not-found",
+		"User not found":  "user-not-found",
+		"QUOTA_EXCEEDED":  "quota-exceeded",
+		" storage (v2)! ": "storage-v2",
+	}
+
+	for input, expected := range testCases {
+		if anchor := gerrors.DocsAnchor(input); anchor != expected {
+			t.Errorf("expected anchor of %q to be %s, got %s", input, expected, anchor)
+		}
+	}
+}
+
+func TestIdentifierWords(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string][]string{
+		"user-not-found":  {"user", "not", "found"},
+		"User_not found":  {"user", "not", "found"},
+		"QUOTA_EXCEEDED":  {"quota", "exceeded"},
+		" storage (v2)! ": {"storage", "v2"},
+		"--":              nil,
+	}
+
+	for input, expected := range testCases {
+		if words := gerrors.IdentifierWords(input); !slices.Equal(words, expected) {
+			t.Errorf("expected words of %q to be %v, got %v", input, expected, words)
+		}
+	}
+}
+
+func TestWriteDocs(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := gerrors.ParseCatalog(strings.NewReader(testCatalog))
+	if err != nil {
+		t.Fatalf("unexpected error parsing catalog: %v", err)
+	}
+
+	testCases := []struct {
+		format   gerrors.DocsFormat
+		expected []string
+	}{
+		{
+			format: gerrors.DocsMarkdown,
+			expected: []string{
+				"# Errors",
+				"- [user-not-found](#user-not-found) (100)",
+				`<a id="user-not-found"></a>`,
+				"> **Deprecated:** use account-not-found instead",
+				"| gRPC code | `NotFound` |",
+				"| HTTP status | `429` |",
+				"| Retryable | yes |",
+				`"reason": "USER-NOT-FOUND"`,
+				`"user_id": "\u003cuser_id\u003e"`,
+			},
+		},
+		{
+			format: gerrors.DocsHTML,
+			expected: []string{
+				"<title>Errors</title>",
+				`<section id="user-not-found">`,
+				"<strong>Deprecated:</strong> use account-not-found instead",
+				"<tr><th>gRPC code</th><td><code>ResourceExhausted</code></td></tr>",
+				"user {{.user_id}} was not found",
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(string(tc.format), func(t *testing.T) {
+			t.Parallel()
+
+			var first, second bytes.Buffer
+
+			if err := gerrors.WriteDocs(&first, catalog.Mapper(), tc.format, gerrors.WithDocsTitle("Errors")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := gerrors.WriteDocs(&second, catalog.Mapper(), tc.format, gerrors.WithDocsTitle("Errors")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if first.String() != second.String() {
+				t.Errorf("expected docs to be stable")
+			}
+
+			for _, expected := range tc.expected {
+				if !strings.Contains(first.String(), expected) {
+					t.Errorf("expected docs to contain %q:\n%s", expected, first.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteDocsUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	mapper := gerrors.NewMapper(gerrors.Unknown, gerrors.GetDefaultMapping())
+
+	var buf bytes.Buffer
+
+	if err := gerrors.WriteDocs(&buf, mapper, "pdf"); err == nil {
+		t.Errorf("expected error for unknown format")
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be written for unknown format, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteDocsAnchorCollision(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := gerrors.ParseCatalog(strings.NewReader(`{
+  "unknown_code": 1,
+  "errors": [
+    {"code": 1, "identifier": "unknown", "message": "unknown error"},
+    {"code": 100, "identifier": "user not found", "message": "user was not found"},
+    {"code": 101, "identifier": "user-not-found", "message": "user was not found"}
+  ]
+}`))
+	if err != nil {
+		t.Fatalf("unexpected error parsing catalog: %v", err)
+	}
+
+	for _, format := range []gerrors.DocsFormat{gerrors.DocsMarkdown, gerrors.DocsHTML} {
+		var buf bytes.Buffer
+
+		err := gerrors.WriteDocs(&buf, catalog.Mapper(), format)
+		if err == nil || !strings.Contains(err.Error(), `"user not found" and "user-not-found"`) {
+			t.Errorf("expected %s docs to reject the anchor collision, got %v", format, err)
+		}
+
+		if buf.Len() != 0 {
+			t.Errorf("expected nothing to be written on anchor collision, got:\n%s", buf.String())
+		}
+	}
+}
+
+func TestWriteDocsMarkdownEscaping(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := gerrors.ParseCatalog(strings.NewReader(`{
+  "unknown_code": 1,
+  "errors": [
+    {"code": 1, "identifier": "unknown", "message": "unknown error"},
+    {
+      "code": 100,
+      "identifier": "invalid-range",
+      "message": "invalid range",
+      "message_template": "range {{.from}} | {{.to}}\nis invalid",
+      "severity": "low|high",
+      "labels": [{"key": "from", "description": "start of the range,\n a | separated list"}, {"key": "to"}]
+    }
+  ]
+}`))
+	if err != nil {
+		t.Fatalf("unexpected error parsing catalog: %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := gerrors.WriteDocs(&buf, catalog.Mapper(), gerrors.DocsMarkdown); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"| Message template | `range {{.from}} \\| {{.to}} is invalid` |",
+		"| Severity | low\\|high |",
+		"| start of the range,<br> a \\| separated list |",
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("expected docs to contain %q:\n%s", line, buf.String())
+		}
+	}
+}
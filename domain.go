@@ -0,0 +1,138 @@
+package gerrors
+
+import (
+	"errors"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// WithDomain sets the domain of the errors of the formatter, e.g. "billing.example.com".
+// The domain is sent as the Domain of the [errdetails.ErrorInfo], which is required by
+// AIP-193, and as the [MetadataDomain] label. Codes whose [CoreError] implements
+// [CoreDomainError] use their own domain instead.
+func WithDomain(domain string) FormatterOption {
+	return func(f *Formatter) {
+		f.domain = domain
+	}
+}
+
+// Domain returns the domain of the error. It is the domain of the error's code if its
+// [CoreError] implements [CoreDomainError], and the formatter's domain otherwise.
+// It is empty if neither has a domain.
+func (ge *GeneralError) Domain() string {
+	return ge.formatter.domainOf(ge.coreError)
+}
+
+// QualifiedCode returns the (domain, code) pair of the error as a single string, e.g.
+// "billing.example.com/100", or only the code if the error has no domain. It is meant to
+// identify errors in metrics, e.g. as the label of an error counter, so that the same code
+// of different domains is never counted together.
+func (ge *GeneralError) QualifiedCode() string {
+	code := strconv.Itoa(int(ge.Code()))
+
+	if domain := ge.Domain(); domain != "" {
+		return domain + "/" + code
+	}
+
+	return code
+}
+
+// LookupCode is the reverse of [GeneralError.Domain] and [GeneralError.Code]. It returns
+// the [CoreError] of the (domain, code) pair, if the formatter's lookuper has an explicit
+// mapping for the code and the code belongs to the domain.
+func (f *Formatter) LookupCode(domain string, code Code) (CoreError, bool) {
+	core, ok := tryLookup(f.coreDataLookup, code)
+	if !ok || f.domainOf(core) != domain {
+		return nil, false
+	}
+
+	return core, true
+}
+
+// LookupReason returns the [CoreError] of the (domain, reason) pair, e.g. the reason and
+// domain of an [errdetails.ErrorInfo] that was received from another service.
+// The formatter's lookuper has to implement [EnumerableLookuper].
+func (f *Formatter) LookupReason(domain, reason string) (CoreError, bool) {
+	l, ok := f.coreDataLookup.(EnumerableLookuper)
+	if !ok {
+		return nil, false
+	}
+
+	for _, core := range l.All() {
+		if f.reasonOf(core) == reason && f.domainOf(core) == domain {
+			return core, true
+		}
+	}
+
+	return nil, false
+}
+
+// domainOf returns the domain of the core error, falling back to the formatter's domain.
+func (f *Formatter) domainOf(core CoreError) string {
+	if cored, ok := core.(CoreDomainError); ok && cored.GetDomain() != "" {
+		return cored.GetDomain()
+	}
+
+	return f.domain
+}
+
+// IsCode reports whether err is an error of the given (domain, code) pair.
+// err can be a [GeneralError] or a gRPC error that was created by [GrpcError],
+// e.g. an error returned by another service, in which case the code and domain
+// are read from its [errdetails.ErrorInfo] detail. The code of a gRPC error is read from
+// the [MetadataErrorCode] label, by its default or lowerCamelCase name, so errors whose sender
+// renamed or disabled the label using [WithSystemKey] never match; use [IsReason] instead,
+// which only relies on the reason and the domain of the detail.
+//
+//	if gerrors.IsCode(err, "billing.example.com", billing.InvoiceNotFound) {
+//		...
+//	}
+func IsCode(err error, domain string, code Code) bool {
+	var ge *GeneralError
+	if errors.As(err, &ge) {
+		return ge.Domain() == domain && ge.Code() == code
+	}
+
+	info := errorInfoOf(err)
+	if info == nil || info.GetDomain() != domain {
+		return false
+	}
+
+	errorCode, ok := info.GetMetadata()[MetadataErrorCode]
+	if !ok {
+		errorCode = info.GetMetadata()[KeyCaseLowerCamel.convert(MetadataErrorCode)]
+	}
+
+	return errorCode == strconv.Itoa(int(code))
+}
+
+// IsReason reports whether err is an error of the given (domain, reason) pair.
+// Check [IsCode] for the supported errors.
+func IsReason(err error, domain, reason string) bool {
+	var ge *GeneralError
+	if errors.As(err, &ge) {
+		return ge.Domain() == domain && ge.formatter.reasonOf(ge.coreError) == reason
+	}
+
+	info := errorInfoOf(err)
+
+	return info != nil && info.GetDomain() == domain && info.GetReason() == reason
+}
+
+// errorInfoOf returns the [errdetails.ErrorInfo] detail of a gRPC error, if it has any.
+func errorInfoOf(err error) *errdetails.ErrorInfo {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+
+	return nil
+}
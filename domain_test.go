@@ -0,0 +1,217 @@
+package gerrors_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithDomain(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		formatter         *gerrors.Formatter
+		code              gerrors.Code
+		expectedDomain    string
+		expectedQualified string
+	}{
+		{
+			name:              "no domain",
+			formatter:         gerrors.NewFormatter(),
+			code:              gerrors.NotFound,
+			expectedDomain:    "",
+			expectedQualified: "2",
+		},
+		{
+			name:              "formatter domain",
+			formatter:         gerrors.NewFormatter(gerrors.WithDomain("billing.example.com")),
+			code:              gerrors.NotFound,
+			expectedDomain:    "billing.example.com",
+			expectedQualified: "billing.example.com/2",
+		},
+		{
+			name:              "core domain",
+			formatter:         domainFormatter(),
+			code:              100,
+			expectedDomain:    "identity.example.com",
+			expectedQualified: "identity.example.com/100",
+		},
+		{
+			name:              "core without domain",
+			formatter:         domainFormatter(),
+			code:              1,
+			expectedDomain:    "billing.example.com",
+			expectedQualified: "billing.example.com/1",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.formatter.New(nil, tc.code)
+
+			if err.Domain() != tc.expectedDomain {
+				t.Errorf("expected domain %q, got %q", tc.expectedDomain, err.Domain())
+			}
+
+			if err.QualifiedCode() != tc.expectedQualified {
+				t.Errorf("expected qualified code %q, got %q", tc.expectedQualified, err.QualifiedCode())
+			}
+
+			domain, ok := err.Metadata()[gerrors.MetadataDomain]
+			if domain != tc.expectedDomain || ok != (tc.expectedDomain != "") {
+				t.Errorf("expected domain label %q, got %q", tc.expectedDomain, domain)
+			}
+
+			st, _ := status.FromError(err.Grpc())
+
+			for _, detail := range st.Details() {
+				if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetDomain() != tc.expectedDomain {
+					t.Errorf("expected error info domain %q, got %q", tc.expectedDomain, info.GetDomain())
+				}
+			}
+
+			out, _ := err.RenderWith(gerrors.JSONRenderer{})
+			if strings.Contains(out, `"domain"`) != (tc.expectedDomain != "") {
+				t.Errorf("unexpected domain in rendered error: %s", out)
+			}
+		})
+	}
+}
+
+func TestIsCode(t *testing.T) {
+	t.Parallel()
+
+	billing := gerrors.NewFormatter(gerrors.WithDomain("billing.example.com"))
+	identity := gerrors.NewFormatter(gerrors.WithDomain("identity.example.com"))
+
+	testCases := []struct {
+		name           string
+		err            error
+		expectedCode   bool
+		expectedReason bool
+	}{
+		{
+			name:           "same domain",
+			err:            billing.New(nil, gerrors.NotFound),
+			expectedCode:   true,
+			expectedReason: true,
+		},
+		{
+			name:           "other domain",
+			err:            identity.New(nil, gerrors.NotFound),
+			expectedCode:   false,
+			expectedReason: false,
+		},
+		{
+			name:           "wrapped error",
+			err:            gerrors.Wrap(billing.New(nil, gerrors.NotFound), "repo"),
+			expectedCode:   true,
+			expectedReason: true,
+		},
+		{
+			name:           "grpc error",
+			err:            billing.New(nil, gerrors.NotFound).Grpc(),
+			expectedCode:   true,
+			expectedReason: true,
+		},
+		{
+			name:           "grpc error of other domain",
+			err:            identity.New(nil, gerrors.NotFound).Grpc(),
+			expectedCode:   false,
+			expectedReason: false,
+		},
+		{
+			name: "grpc error with renamed code key",
+			err: gerrors.NewFormatter(
+				gerrors.WithDomain("billing.example.com"),
+				gerrors.WithSystemKey(gerrors.MetadataErrorCode, "error_code"),
+			).New(nil, gerrors.NotFound).Grpc(),
+			expectedCode:   false,
+			expectedReason: true,
+		},
+		{
+			name: "grpc error with disabled code key",
+			err: gerrors.NewFormatter(
+				gerrors.WithDomain("billing.example.com"),
+				gerrors.WithSystemKey(gerrors.MetadataErrorCode, ""),
+			).New(nil, gerrors.NotFound).Grpc(),
+			expectedCode:   false,
+			expectedReason: true,
+		},
+		{
+			name:           "other code",
+			err:            billing.New(nil, gerrors.Internal),
+			expectedCode:   false,
+			expectedReason: false,
+		},
+		{
+			name:           "plain error",
+			err:            errors.New("not found"),
+			expectedCode:   false,
+			expectedReason: false,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if ok := gerrors.IsCode(tc.err, "billing.example.com", gerrors.NotFound); ok != tc.expectedCode {
+				t.Errorf("expected IsCode %v, got %v", tc.expectedCode, ok)
+			}
+
+			if ok := gerrors.IsReason(tc.err, "billing.example.com", "NOT-FOUND"); ok != tc.expectedReason {
+				t.Errorf("expected IsReason %v, got %v", tc.expectedReason, ok)
+			}
+		})
+	}
+}
+
+func TestFormatterLookupDomain(t *testing.T) {
+	t.Parallel()
+
+	f := domainFormatter()
+
+	if core, ok := f.LookupCode("identity.example.com", 100); !ok || core.GetIdentifier() != "user-not-found" {
+		t.Errorf("expected identity code, got %v %v", core, ok)
+	}
+
+	if _, ok := f.LookupCode("billing.example.com", 100); ok {
+		t.Errorf("expected no billing code 100")
+	}
+
+	if core, ok := f.LookupReason("billing.example.com", "UNKNOWN"); !ok || core.GetInternalCode() != 1 {
+		t.Errorf("expected billing reason, got %v %v", core, ok)
+	}
+
+	if _, ok := f.LookupReason("billing.example.com", "USER-NOT-FOUND"); ok {
+		t.Errorf("expected no billing reason USER-NOT-FOUND")
+	}
+}
+
+// domainFormatter has the billing domain, and a catalog whose code 100 belongs to the identity domain.
+func domainFormatter() *gerrors.Formatter {
+	mapper, err := gerrors.LoadCatalog(strings.NewReader(`{
+  "unknown_code": 1,
+  "errors": [
+    {"code": 1, "identifier": "unknown", "message": "unknown error"},
+    {"code": 100, "identifier": "user-not-found", "message": "user was not found", "domain": "identity.example.com"}
+  ]
+}`))
+	if err != nil {
+		panic(err)
+	}
+
+	return gerrors.NewFormatter(gerrors.WithDomain("billing.example.com"), gerrors.WithLookuper(mapper))
+}
@@ -0,0 +1,191 @@
+package gerrors
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// EnumOption configures how [MapperFromEnum] reads the enum values.
+type EnumOption func(*enumMapper)
+
+type enumMapper struct {
+	unknownCode      Code
+	messageOption    protoreflect.FullName
+	grpcCodeOption   protoreflect.FullName
+	httpStatusOption protoreflect.FullName
+}
+
+// WithEnumUnknownCode sets the enum number that is used as the unknown code
+// of the mapper. It defaults to 0, which is the "UNSPECIFIED" value of
+// proto3 enums.
+func WithEnumUnknownCode(code Code) EnumOption {
+	return func(m *enumMapper) {
+		m.unknownCode = code
+	}
+}
+
+// WithEnumMessageOption sets the full name of a string extension of
+// google.protobuf.EnumValueOptions that holds the default message of the value.
+// e.g. "acme.errors.v1.message"
+func WithEnumMessageOption(name protoreflect.FullName) EnumOption {
+	return func(m *enumMapper) {
+		m.messageOption = name
+	}
+}
+
+// WithEnumGRPCCodeOption sets the full name of an extension of
+// google.protobuf.EnumValueOptions that holds the gRPC code of the value.
+// The extension can be a google.rpc.Code enum, an integer, or a string
+// holding the name of the code, e.g. "NOT_FOUND".
+func WithEnumGRPCCodeOption(name protoreflect.FullName) EnumOption {
+	return func(m *enumMapper) {
+		m.grpcCodeOption = name
+	}
+}
+
+// WithEnumHTTPStatusOption sets the full name of an integer extension of
+// google.protobuf.EnumValueOptions that holds the HTTP status of the value.
+func WithEnumHTTPStatusOption(name protoreflect.FullName) EnumOption {
+	return func(m *enumMapper) {
+		m.httpStatusOption = name
+	}
+}
+
+// MapperFromEnum creates a [Mapper] from a protobuf enum, e.g. an AIP-193
+// ErrorReason enum, so that the proto definition is the single source of
+// truth for both the error reason on the wire and the error codes.
+//
+// Each enum number is used as the [Code] of the value. The identifier is derived
+// from the value name, e.g. USER_NOT_FOUND becomes "user not found", so that the
+// reason of the error details is the value name itself.
+// The default message is read from the extension set by [WithEnumMessageOption]
+// and falls back to the leading comments of the value, if the descriptor has
+// source information. The gRPC code and HTTP status are read from the extensions
+// set by [WithEnumGRPCCodeOption] and [WithEnumHTTPStatusOption].
+// Extensions are only found if their Go types are linked into the binary.
+//
+//	mapper, err := gerrors.MapperFromEnum(
+//		errorsv1.ErrorReason(0).Descriptor(),
+//		gerrors.WithEnumGRPCCodeOption("acme.errors.v1.grpc_code"),
+//	)
+func MapperFromEnum(enum protoreflect.EnumDescriptor, opts ...EnumOption) (*Mapper, error) {
+	m := &enumMapper{
+		unknownCode:      0,
+		messageOption:    "",
+		grpcCodeOption:   "",
+		httpStatusOption: "",
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	values := enum.Values()
+	catalog := &Catalog{
+		UnknownCode: m.unknownCode,
+		Entries:     make([]*CatalogEntry, 0, values.Len()),
+	}
+
+	for i := range values.Len() {
+		entry, err := m.entry(values.Get(i))
+		if err != nil {
+			return nil, err
+		}
+
+		catalog.Entries = append(catalog.Entries, entry)
+	}
+
+	if err := catalog.Validate(); err != nil {
+		return nil, err
+	}
+
+	return catalog.Mapper(), nil
+}
+
+func (m *enumMapper) entry(value protoreflect.EnumValueDescriptor) (*CatalogEntry, error) {
+	entry := &CatalogEntry{
+		Code:       Code(value.Number()),
+		Identifier: strings.ToLower(strings.ReplaceAll(string(value.Name()), "_", " ")),
+		Message:    enumValueComment(value),
+		GRPCCode:   "",
+		HTTPStatus: 0,
+	}
+
+	options := enumValueOptions(value)
+
+	if v, ok := options[m.messageOption]; ok {
+		entry.Message = v.String()
+	}
+
+	if v, ok := options[m.grpcCodeOption]; ok {
+		grpcCode, err := enumGRPCCode(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s has %w", ErrInvalidCatalog, value.FullName(), err)
+		}
+
+		entry.GRPCCode = grpcCode.String()
+	}
+
+	if v, ok := options[m.httpStatusOption]; ok {
+		status, ok := v.Interface().(int32)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has non-integer HTTP status %v", ErrInvalidCatalog, value.FullName(), v)
+		}
+
+		entry.HTTPStatus = int(status)
+	}
+
+	return entry, nil
+}
+
+// enumValueOptions returns the extensions set on the options of the enum value, by full name.
+func enumValueOptions(value protoreflect.EnumValueDescriptor) map[protoreflect.FullName]protoreflect.Value {
+	result := make(map[protoreflect.FullName]protoreflect.Value)
+
+	options := value.Options()
+	if options == nil {
+		return result
+	}
+
+	options.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.IsExtension() {
+			result[fd.FullName()] = v
+		}
+
+		return true
+	})
+
+	return result
+}
+
+// enumGRPCCode translates the value of a gRPC code extension to codes.Code.
+func enumGRPCCode(v protoreflect.Value) (codes.Code, error) {
+	switch value := v.Interface().(type) {
+	case protoreflect.EnumNumber:
+		return codes.Code(value), nil
+	case int32:
+		return codes.Code(value), nil
+	case string:
+		grpcCode, ok := parseGRPCCode(value)
+		if !ok {
+			return codes.Unknown, fmt.Errorf("invalid gRPC code %q", value)
+		}
+
+		return grpcCode, nil
+	default:
+		return codes.Unknown, fmt.Errorf("unsupported gRPC code %v", v)
+	}
+}
+
+// enumValueComment returns the first paragraph of the leading comments of the
+// enum value, if the descriptor has source information.
+func enumValueComment(value protoreflect.EnumValueDescriptor) string {
+	comments := value.ParentFile().SourceLocations().ByDescriptor(value).LeadingComments
+
+	paragraph, _, _ := strings.Cut(strings.TrimSpace(comments), "\n\n")
+
+	return strings.Join(strings.Fields(paragraph), " ")
+}
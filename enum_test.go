@@ -0,0 +1,183 @@
+package gerrors_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// testEnum builds the descriptor of the following file. Options of the values
+// are set using the given option builder.
+//
+//	enum ErrorReason {
+//	  // Reason is not known.
+//	  ERROR_REASON_UNSPECIFIED = 0;
+//	  // User was not found.
+//	  //
+//	  // Check the user id.
+//	  USER_NOT_FOUND = 5 [(grpc_code) = "NOT_FOUND", (http_status) = 410];
+//	  QUOTA_EXCEEDED = 6 [(message) = "quota exceeded", (grpc_code) = "ResourceExhausted"];
+//	}
+func testEnum(t *testing.T) protoreflect.EnumDescriptor {
+	t.Helper()
+
+	extensions := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("errors/options.proto"),
+		Package:    proto.String("errors"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		Syntax:     proto.String("proto3"),
+		Extension: []*descriptorpb.FieldDescriptorProto{
+			testExtension("message", 50001, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			testExtension("grpc_code", 50002, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			testExtension("http_status", 50003, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+		},
+	}
+
+	extFile, err := protodesc.NewFile(extensions, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("failed to build extensions: %v", err)
+	}
+
+	option := func(values map[string]any) *descriptorpb.EnumValueOptions {
+		opts := &descriptorpb.EnumValueOptions{}
+
+		for name, value := range values {
+			xt := dynamicpb.NewExtensionType(extFile.Extensions().ByName(protoreflect.Name(name)))
+			proto.SetExtension(opts, xt, value)
+		}
+
+		return opts
+	}
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("errors/reason.proto"),
+		Package:    proto.String("errors"),
+		Dependency: []string{"errors/options.proto"},
+		Syntax:     proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("ErrorReason"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("ERROR_REASON_UNSPECIFIED"), Number: proto.Int32(0)},
+				{
+					Name:    proto.String("USER_NOT_FOUND"),
+					Number:  proto.Int32(5),
+					Options: option(map[string]any{"grpc_code": "NOT_FOUND", "http_status": int32(410)}),
+				},
+				{
+					Name:    proto.String("QUOTA_EXCEEDED"),
+					Number:  proto.Int32(6),
+					Options: option(map[string]any{"message": "quota exceeded", "grpc_code": "ResourceExhausted"}),
+				},
+			},
+		}},
+		SourceCodeInfo: &descriptorpb.SourceCodeInfo{
+			Location: []*descriptorpb.SourceCodeInfo_Location{
+				{Path: []int32{5, 0, 2, 0}, Span: []int32{2, 2, 30}, LeadingComments: proto.String(" Reason is not known.\n")},
+				{
+					Path:            []int32{5, 0, 2, 1},
+					Span:            []int32{5, 2, 30},
+					LeadingComments: proto.String(" User was not\n found.\n\n Check the user id.\n"),
+				},
+			},
+		},
+	}
+
+	files := new(protoregistry.Files)
+	if err := files.RegisterFile(extFile); err != nil {
+		t.Fatalf("failed to register extensions: %v", err)
+	}
+
+	fd, err := protodesc.NewFile(file, files)
+	if err != nil {
+		t.Fatalf("failed to build enum: %v", err)
+	}
+
+	return fd.Enums().Get(0)
+}
+
+func testExtension(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     kind.Enum(),
+		Extendee: proto.String(".google.protobuf.EnumValueOptions"),
+	}
+}
+
+func TestMapperFromEnum(t *testing.T) {
+	t.Parallel()
+
+	mapper, err := gerrors.MapperFromEnum(
+		testEnum(t),
+		gerrors.WithEnumMessageOption("errors.message"),
+		gerrors.WithEnumGRPCCodeOption("errors.grpc_code"),
+		gerrors.WithEnumHTTPStatusOption("errors.http_status"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		code       gerrors.Code
+		identifier string
+		message    string
+		grpcCode   codes.Code
+		httpStatus int
+	}{
+		{code: 0, identifier: "error reason unspecified", message: "Reason is not known.", grpcCode: codes.Unknown, httpStatus: 500},
+		{code: 5, identifier: "user not found", message: "User was not found.", grpcCode: codes.NotFound, httpStatus: 410},
+		{code: 6, identifier: "quota exceeded", message: "quota exceeded", grpcCode: codes.ResourceExhausted, httpStatus: 429},
+		{code: 7, identifier: "error reason unspecified", message: "Reason is not known.", grpcCode: codes.Unknown, httpStatus: 500},
+	}
+
+	for _, tc := range testCases {
+		core := mapper.Lookup(tc.code)
+
+		if core.GetIdentifier() != tc.identifier || core.GetDefaultMessage() != tc.message {
+			t.Errorf("code %d: unexpected identifier %q or message %q", tc.code, core.GetIdentifier(), core.GetDefaultMessage())
+		}
+
+		if grpcCore, _ := core.(gerrors.CoreGRPCError); grpcCore.GetGRPCCode() != tc.grpcCode {
+			t.Errorf("code %d: expected gRPC code %s, got %s", tc.code, tc.grpcCode, grpcCore.GetGRPCCode())
+		}
+
+		if httpCore, _ := core.(gerrors.CoreHTTPError); httpCore.GetHTTPStatus() != tc.httpStatus {
+			t.Errorf("code %d: expected HTTP status %d, got %d", tc.code, tc.httpStatus, httpCore.GetHTTPStatus())
+		}
+	}
+
+	f := gerrors.NewFormatter(gerrors.WithLookuper(mapper))
+
+	st, _ := status.FromError(gerrors.GrpcError(f.New(nil, 5)))
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetReason() != "USER_NOT_FOUND" {
+			t.Errorf("expected reason to be the enum value name, got %s", info.GetReason())
+		}
+	}
+}
+
+func TestMapperFromEnumInvalid(t *testing.T) {
+	t.Parallel()
+
+	enum := testEnum(t)
+
+	if _, err := gerrors.MapperFromEnum(enum, gerrors.WithEnumUnknownCode(100)); !errors.Is(err, gerrors.ErrInvalidCatalog) {
+		t.Errorf("expected missing unknown code to be invalid, got %v", err)
+	}
+
+	if _, err := gerrors.MapperFromEnum(enum, gerrors.WithEnumGRPCCodeOption("errors.message")); !errors.Is(err, gerrors.ErrInvalidCatalog) {
+		t.Errorf("expected invalid gRPC code to be invalid, got %v", err)
+	}
+}
@@ -1,7 +1,6 @@
 package gerrors
 
 import (
-	"fmt"
 	"regexp"
 	"text/template"
 )
@@ -42,11 +41,28 @@ var (
 // a new formatter.
 type Formatter struct {
 	logger                  logger
-	labels                  map[string]string
-	template                *template.Template
+	labels                  map[string]labelValue
+	renderer                Renderer
+	templateRenderer        *TemplateRenderer
+	templateText            string
+	namedTemplates          map[string]string
+	templateFuncs           template.FuncMap
+	stackTrace              bool
 	allowMissingValue       bool
 	missingValueReplacement string
 	coreDataLookup          Lookuper
+	valueEncoders           map[ValueKind]ValueEncoder
+	localizer               *Localizer
+	domain                  string
+	keyCase                 KeyCase
+	aip193                  bool
+	reservedKeyPolicy       ReservedKeyPolicy
+	labelConflictPolicy     LabelConflictPolicy
+	systemKeyNames          map[string]string
+	budget                  *MetadataBudget
+	redactionRules          []RedactionRule
+	redactionSalt           []byte
+	labelValidation         LabelValidation
 }
 
 // FormatterOption is the approach for customizing the formatter.
@@ -58,36 +74,83 @@ type FormatterOption func(*Formatter)
 // Check [DefaultFormatter] for more information on the default options.
 // It accepts a variadic number of FormatterOptions for customizing the returned
 // formatter. Check helper functions that returns [FormatterOption] for more information.
+// NewFormatter panics if the configured templates are invalid, or if the formatter
+// is in strict AIP-193 mode and violates it. Use [NewFormatterE] to get an error instead.
 func NewFormatter(opts ...FormatterOption) *Formatter {
-	tpl, err := template.New("gerror").Parse(defaultTemplate)
+	f, err := NewFormatterE(opts...)
 	if err != nil {
 		panic(err)
 	}
 
+	return f
+}
+
+// NewFormatterE is the same as [NewFormatter], but it returns an error instead of panicking.
+// In strict AIP-193 mode, every violation is reported, wrapping [ErrAIP193Violation].
+// Check [WithAIP193] for more information.
+func NewFormatterE(opts ...FormatterOption) (*Formatter, error) {
 	defaultLookuper := NewMapper(Unknown, GetDefaultMapping())
 
 	f := &Formatter{
-		labels:                  make(map[string]string),
-		template:                tpl,
+		labels:                  make(map[string]labelValue),
+		renderer:                nil,
+		templateRenderer:        nil,
+		templateText:            defaultTemplate,
+		namedTemplates:          make(map[string]string),
+		templateFuncs:           make(template.FuncMap),
+		stackTrace:              false,
 		allowMissingValue:       true,
 		missingValueReplacement: missingValueReplacement,
 		coreDataLookup:          defaultLookuper,
 		logger:                  nil,
+		valueEncoders:           getDefaultValueEncoders(),
+		localizer:               nil,
+		domain:                  "",
+		keyCase:                 KeyCaseOriginal,
+		aip193:                  false,
+		reservedKeyPolicy:       ReservedKeyReject,
+		labelConflictPolicy:     LabelConflictOverride,
+		systemKeyNames:          nil,
+		budget:                  nil,
+		redactionRules:          nil,
+		redactionSalt:           nil,
+		labelValidation:         LabelValidationOff,
 	}
 
 	for _, opt := range opts {
 		opt(f)
 	}
 
-	return f
+	tpl, err := newTemplateRenderer(f.templateText, f.namedTemplates, f.templateFuncs)
+	if err != nil {
+		return nil, err
+	}
+
+	f.templateRenderer = tpl
+
+	if f.renderer == nil {
+		f.renderer = tpl
+	}
+
+	if f.aip193 {
+		if err := f.ValidateAIP193(); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
 }
 
 // WithTemplate customizes formatter defaultTemplate.
-// This template should follow text/template syntax. Function panics if template is invalid.
+// This template should follow text/template syntax. [NewFormatter] panics if template is invalid.
+// Besides the functions registered by [WithTemplateFuncs], the template can use the
+// built-in functions explained in [WithTemplateFuncs].
 // Supported variables are:
 //
 //   - {{.Identifier}}: the identifier of the error. (e.g. unavailable, internal, ...)
 //
+//   - {{.Domain}}: the domain of the error, if it has one. (e.g. billing.example.com)
+//
 //   - {{.ErrorCode}}: core error code. (e.g. 1, 2, ...)
 //
 //   - {{.GrpcErrorCode}}: grpc error code. (e.g. 2, 5, ...)
@@ -96,17 +159,17 @@ func NewFormatter(opts ...FormatterOption) *Formatter {
 //
 //   - {{.DefaultMessage}}: the default message of the error code.
 //
+//   - {{.Operation}}: the chain of operations the error has passed through. (e.g. handler > repo)
+//
 //   - {{.Labels}}: formatter's label plus error-specific labels. Treat it as a map.
 //
+//   - {{.LocalizedMessage}}, {{.Locale}}: the message in the requester's preferred locale and its locale,
+//     only when rendered by [GeneralError.RenderWithContext].
+//
 //     f := NewFormatter(WithTemplate("error: {{.Identifier}}(code {{.ErrorCode}}) - {{.Message}}"))
 func WithTemplate(templateString string) FormatterOption {
-	tpl, err := template.New("gerror").Parse(templateString)
-	if err != nil {
-		panic(err)
-	}
-
 	return func(f *Formatter) {
-		f.template = tpl
+		f.templateText = templateString
 	}
 }
 
@@ -153,19 +216,29 @@ func WithDisabledMissingValueReplacement() FormatterOption {
 	}
 }
 
+// WithValueEncoder customizes how label values of the given kind are converted
+// to string. Label values keep their type until a string representation is
+// required, e.g. in [GeneralError.Metadata] or gRPC error details, while
+// loggers receive the typed values.
+// Defaults are RFC 3339 for times, [time.Duration.String] for durations,
+// hex for byte slices, JSON for structs, maps and slices, and fmt's %v verb
+// for the rest, which supports [fmt.Stringer] as well.
+//
+//	f := NewFormatter(WithValueEncoder(KindTime, func(v any) string {
+//		return v.(time.Time).UTC().Format(time.RFC3339Nano)
+//	}))
+func WithValueEncoder(kind ValueKind, encoder ValueEncoder) FormatterOption {
+	return func(f *Formatter) {
+		f.valueEncoders[kind] = encoder
+	}
+}
+
 // WithLabels add a set of default labels to the formatter.
 // All these labels will be included in every error generated by the formatter.
 // It can be used to group errors together in a function scope or a call scope.
 func WithLabels(keyValues ...any) FormatterOption {
 	return func(f *Formatter) {
-		for i := 0; i < len(keyValues); i += 2 {
-			key, val, ok := f.getStringifiedKeyValue(keyValues, i)
-			if !ok {
-				continue
-			}
-
-			f.labels[key] = val
-		}
+		f.parseKeyValues(keyValues, f.labels)
 	}
 }
 
@@ -176,35 +249,50 @@ func WithLabels(keyValues ...any) FormatterOption {
 func (f *Formatter) Clone() *Formatter {
 	newF := Formatter{
 		logger:                  f.logger,
-		labels:                  make(map[string]string),
-		template:                f.template,
+		labels:                  make(map[string]labelValue),
+		renderer:                f.renderer,
+		templateRenderer:        f.templateRenderer,
+		templateText:            f.templateText,
+		namedTemplates:          f.namedTemplates,
+		templateFuncs:           f.templateFuncs,
+		stackTrace:              f.stackTrace,
 		allowMissingValue:       f.allowMissingValue,
 		missingValueReplacement: f.missingValueReplacement,
 		coreDataLookup:          f.coreDataLookup,
+		valueEncoders:           make(map[ValueKind]ValueEncoder),
+		localizer:               f.localizer,
+		domain:                  f.domain,
+		keyCase:                 f.keyCase,
+		aip193:                  f.aip193,
+		reservedKeyPolicy:       f.reservedKeyPolicy,
+		labelConflictPolicy:     f.labelConflictPolicy,
+		systemKeyNames:          f.systemKeyNames,
+		budget:                  f.budget,
+		redactionRules:          f.redactionRules,
+		redactionSalt:           f.redactionSalt,
+		labelValidation:         f.labelValidation,
 	}
 
 	for k, v := range f.labels {
 		newF.labels[k] = v
 	}
 
+	for k, v := range f.valueEncoders {
+		newF.valueEncoders[k] = v
+	}
+
 	return &newF
 }
 
 // AddLabels adds a set of labels to the formatter.
 // keyValues should be pairs of data, where the first element is a key and must be a
 // string and follows [maxKeyLength] and [keyRE].
-// The second element is the value and will be converted to string. If value is missing
+// The second element is the value and keeps its type until it is encoded to string.
+// Check [WithValueEncoder] for more information. If value is missing
 // [missingValueReplacement] and [allowMissingValue] are used to decide how to handle it.
 // If the key has invalid characters or is too long, it will be modified to a valid key.
 func (f *Formatter) AddLabels(keyValues ...any) *Formatter {
-	for i := 0; i < len(keyValues); i += 2 {
-		key, val, ok := f.getStringifiedKeyValue(keyValues, i)
-		if !ok {
-			continue
-		}
-
-		f.labels[key] = val
-	}
+	f.parseKeyValues(keyValues, f.labels)
 
 	return f
 }
@@ -224,7 +312,7 @@ func (f *Formatter) LabelsSlice() []string {
 	index := 0
 
 	for k, v := range f.labels {
-		labels[index], labels[index+1] = k, v
+		labels[index], labels[index+1] = k, f.encodeValue(v)
 		index += 2
 	}
 
@@ -234,13 +322,34 @@ func (f *Formatter) LabelsSlice() []string {
 // LabelsMap returns formatter's default labels as a map.
 // To have a slice of labels, use LabelsSlice.
 func (f *Formatter) LabelsMap() map[string]string {
-	return f.labels
+	labels := make(map[string]string, len(f.labels))
+
+	for k, v := range f.labels {
+		labels[k] = f.encodeValue(v)
+	}
+
+	return labels
+}
+
+// parseKeyValues validates the key values and adds them to the given labels.
+// [Labels] elements are expanded to their key values beforehand.
+func (f *Formatter) parseKeyValues(keyValues []any, labels map[string]labelValue) {
+	keyValues = flattenKeyValues(keyValues)
+
+	for i := 0; i < len(keyValues); i += 2 {
+		key, val, ok := f.getKeyValue(keyValues, i)
+		if !ok {
+			continue
+		}
+
+		labels[key] = val
+	}
 }
 
-func (f *Formatter) getStringifiedKeyValue(keyValues []any, keyIndex int) (string, string, bool) {
+func (f *Formatter) getKeyValue(keyValues []any, keyIndex int) (string, labelValue, bool) {
 	key, ok := keyValues[keyIndex].(string)
 	if !ok {
-		return "", "", false
+		return "", labelValue{}, false
 	}
 
 	// Ensure that key follows the standard format
@@ -251,11 +360,11 @@ func (f *Formatter) getStringifiedKeyValue(keyValues []any, keyIndex int) (strin
 		key = key[:keyMaxLength-1]
 	}
 
-	value := f.missingValueReplacement
+	value := stringLabelValue(f.missingValueReplacement)
 	if keyIndex+1 < len(keyValues) {
-		value = fmt.Sprintf("%v", keyValues[keyIndex+1])
+		value = newLabelValue(keyValues[keyIndex+1])
 	} else if !f.allowMissingValue {
-		return "", "", false
+		return "", labelValue{}, false
 	}
 
 	return key, value, true
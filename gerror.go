@@ -1,15 +1,17 @@
 package gerrors
 
 import (
-	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
 )
 
 const (
@@ -27,28 +29,75 @@ const (
 	// MetadataOriginalError is the key for accessing the original error
 	// which was used during initializing GeneralError.
 	MetadataOriginalError = "_original_error"
+
+	// MetadataOperation is the key for accessing the chain of operations
+	// the error has passed through. It is only set if the error has been
+	// annotated using [Wrap] or [Annotate].
+	MetadataOperation = "_operation"
+
+	// MetadataRequestedCode is the key for accessing the code that was requested
+	// when the error was created. It is only set if the lookuper had no mapping
+	// for that code and the error fell back to another code, e.g. the unknown code.
+	MetadataRequestedCode = "_requested_code"
+
+	// MetadataDomain is the key for accessing the domain of the error.
+	// It is only set if the error has a domain. Check [WithDomain] for more information.
+	MetadataDomain = "_domain"
+
+	// MetadataTruncated is the key for accessing the comma-separated label keys that were
+	// truncated or dropped to fit the metadata budget. Keys that do not fit the budget themselves
+	// are counted instead, e.g. "_original_error,+2". Check [WithMetadataBudget].
+	MetadataTruncated = "_truncated"
+
+	// operationSeparator joins the operations of an error, outermost first.
+	operationSeparator = " > "
 )
 
+// systemKeys are the metadata keys that are set by gerrors itself.
+var systemKeys = []string{
+	MetadataIdentifier,
+	MetadataErrorCode,
+	MetadataDefaultMessage,
+	MetadataOriginalError,
+	MetadataOperation,
+	MetadataRequestedCode,
+	MetadataDomain,
+	MetadataTruncated,
+}
+
+// InheritCode can be passed to [Formatter.New] instead of an explicit [Code].
+// If the input error is already a [GeneralError], the new error inherits its
+// code. Otherwise, the formatter's unknown error code is used.
+const InheritCode Code = -1
+
 // GeneralError is the error type defined, controlled, and handled by gerrors package.
 type GeneralError struct {
 	originalError error
 	coreError     CoreError
 	formatter     *Formatter
+	requestedCode Code
+	fallback      bool
+	operations    []string
+	stack         []uintptr
+	defaultLabels map[string]labelValue
+	labels        map[string]labelValue
+	detailsOnce   sync.Once
+	metadata      map[string]labelValue
+	labelStrings  map[string]string
+	message       string
 	details       *errdetails.ErrorInfo
 }
 
-// tplData is used to populate each error's information and then parse the template.
-type tplData struct {
-	Identifier     string
-	ErrorCode      string
-	GrpcErrorCode  string
-	Message        string
-	DefaultMessage string
-	Labels         map[string]string
-}
+var (
+	// errNoOriginalError is set as the input error whenever there is no original error.
+	errNoOriginalError = errors.New("no original error")
+
+	// errUnmappedCode is reported whenever an error is created with a code that has no mapping.
+	errUnmappedCode = errors.New("error code has no mapping, falling back")
 
-// errNoOriginalError is set as the input error whenever there is no original error.
-var errNoOriginalError = errors.New("no original error")
+	// errUnknownTemplate is returned when rendering a template that is not defined.
+	errUnknownTemplate = errors.New("template is not defined")
+)
 
 // GrpcError accepts an error of gerrors.GeneralError type and returns a gRPC error
 // by translating the error to gRPC error and attach all labels as the metadata.
@@ -62,6 +111,18 @@ var errNoOriginalError = errors.New("no original error")
 // [Google's AIP 193]: https://google.aip.dev/193
 // [this blog post]: https://jbrandhorst.com/post/grpc-errors
 func GrpcError(err error) error {
+	return grpcError(err, "", false)
+}
+
+// GrpcErrorContext is the same as [GrpcError], but if the error's formatter has a
+// localizer, it also attaches the error message in the requester's preferred locale
+// as a [errdetails.LocalizedMessage]. The preferred locales are read from the context.
+// Check [LocaleFromContext] and [WithLocalizer] for more information.
+func GrpcErrorContext(ctx context.Context, err error) error {
+	return grpcError(err, LocaleFromContext(ctx), true)
+}
+
+func grpcError(err error, acceptLanguage string, localize bool) error {
 	var finalErr *GeneralError
 
 	if !errors.As(err, &finalErr) {
@@ -71,12 +132,37 @@ func GrpcError(err error) error {
 	grpcErr, ok := finalErr.coreError.(CoreGRPCError)
 
 	if !ok {
-		return status.Error(codes.Unknown, finalErr.Error())
+		return status.Error(codes.Unknown, finalErr.formatter.budgetMessage(finalErr.Error()))
+	}
+
+	// OK would turn the status into a nil error, so the error is reported as Unknown instead.
+	grpcCode := grpcErr.GetGRPCCode()
+	if grpcCode == codes.OK {
+		grpcCode = codes.Unknown
 	}
 
-	st := status.New(grpcErr.GetGRPCCode(), finalErr.Error())
+	st := status.New(grpcCode, finalErr.formatter.budgetMessage(finalErr.Error()))
+	details := []protoadapt.MessageV1{finalErr.transportDetails()}
 
-	finalStatus, err := st.WithDetails(finalErr.details)
+	if coreh, ok := finalErr.coreError.(CoreHelpError); ok && coreh.GetHelpURL() != "" {
+		details = append(details, &errdetails.Help{
+			Links: []*errdetails.Help_Link{{
+				Description: finalErr.formatter.budgetMessage(finalErr.coreError.GetDefaultMessage()),
+				Url:         coreh.GetHelpURL(),
+			}},
+		})
+	}
+
+	if localize {
+		if msg, locale, ok := finalErr.localize(acceptLanguage); ok {
+			details = append(details, &errdetails.LocalizedMessage{
+				Locale:  locale,
+				Message: finalErr.formatter.budgetMessage(msg),
+			})
+		}
+	}
+
+	finalStatus, err := st.WithDetails(details...)
 	if err != nil {
 		finalStatus = st
 	}
@@ -88,13 +174,21 @@ func GrpcError(err error) error {
 // inputErr is the error that is triggered prior to the creation of the error
 // and it can be nil. If it's nil, the final error message will be the code's
 // default message.
+// If inputErr is already a [GeneralError], or wraps one, its original error, labels and
+// operations are carried over to the new error. The context added by the wrapping errors
+// is kept in the original error, e.g. "loading user: db down" for an inputErr created by
+// fmt.Errorf("loading user: %w", ge) where ge has "db down" as its original error. Pass [InheritCode] as the code
+// to keep the error code of inputErr as well.
 // Any new error can have a list of key values as the metadata. These key values
 // will be appended to the formatter's default labels.
+// If the code declares a label schema, the labels are checked against it based on the
+// formatter's label validation mode. Check [WithLabelValidation].
 // If the formatter has a logger, it will also log the error at Error level.
 func (f *Formatter) New(inputErr error, code Code, metadataKeyValues ...any) *GeneralError {
-	err := f.createError(inputErr, code, metadataKeyValues...)
+	err := f.createError(inputErr, code)
 
-	err.log(f.logger, LogLevelError, err.MetadataSlice())
+	f.validateLabels(err, err.addLabels(metadataKeyValues))
+	err.log(f.logger, LogLevelError)
 
 	return err
 }
@@ -108,48 +202,177 @@ func (f *Formatter) NewWithLogLevel(
 	level LogLevel,
 	metadataKeyValues ...any,
 ) *GeneralError {
-	err := f.createError(inputErr, code, metadataKeyValues...)
+	err := f.createError(inputErr, code)
 
-	err.log(f.logger, level, err.MetadataSlice())
+	f.validateLabels(err, err.addLabels(metadataKeyValues))
+	err.log(f.logger, level)
 
 	return err
 }
 
-func (f *Formatter) createError(inputErr error, code Code, metadataKeyValues ...any) *GeneralError {
+func (f *Formatter) createError(inputErr error, code Code) *GeneralError {
 	if inputErr == nil {
 		inputErr = errNoOriginalError
 	}
 
 	err := &GeneralError{
 		originalError: inputErr,
-		coreError:     f.coreDataLookup.Lookup(code),
+		coreError:     nil,
 		formatter:     f,
+		requestedCode: code,
+		fallback:      false,
+		operations:    nil,
+		stack:         nil,
+		defaultLabels: make(map[string]labelValue, len(f.labels)),
+		labels:        make(map[string]labelValue),
+		metadata:      nil,
+		labelStrings:  nil,
+		message:       "",
 		details:       nil,
 	}
 
-	err.generateDetails(metadataKeyValues, f.labels)
+	for k, v := range f.labels {
+		err.defaultLabels[k] = v
+	}
+
+	var inner *GeneralError
+	if errors.As(inputErr, &inner) {
+		err.originalError = inner.contextError(inputErr)
+		err.operations = inner.operations
+		err.stack = inner.stack
+
+		for k, v := range inner.defaultLabels {
+			// The formatter's own labels are not carried over as error labels,
+			// so that they do not conflict with themselves.
+			if fv, ok := f.labels[k]; ok && sameLabelValue(fv, v) {
+				continue
+			}
+
+			err.labels[k] = v
+		}
+
+		for k, v := range inner.labels {
+			err.labels[k] = v
+		}
+
+		if code == InheritCode {
+			err.coreError = inner.coreError
+			err.requestedCode = inner.requestedCode
+			err.fallback = inner.fallback
+		}
+	}
+
+	if err.coreError == nil {
+		err.coreError = f.lookup(code)
+		err.fallback = code != InheritCode && err.coreError.GetInternalCode() != code
+	}
+
+	if f.stackTrace && err.stack == nil {
+		err.stack = captureStack()
+	}
 
 	return err
 }
 
+// contextError is an original error that keeps the context added by the errors wrapping
+// a [GeneralError]. It unwraps to the wrapping error, so its whole chain is still available.
+type contextError struct {
+	message string
+	err     error
+}
+
+func (e *contextError) Error() string {
+	return e.message
+}
+
+func (e *contextError) Unwrap() error {
+	return e.err
+}
+
+// contextError returns the original error of ge for a new error created from err, which is
+// either ge or an error wrapping it. In the latter case, the message of ge is replaced with
+// its original error, or its default message, within the message of err.
+func (ge *GeneralError) contextError(err error) error {
+	if err == error(ge) {
+		return ge.originalError
+	}
+
+	ge.getDetails()
+
+	message := ge.message
+	if !errors.Is(ge.originalError, errNoOriginalError) {
+		message = ge.originalError.Error()
+	}
+
+	return &contextError{message: strings.Replace(err.Error(), ge.Error(), message, 1), err: err}
+}
+
+// lookup translates the code using the formatter's lookuper. It never returns nil,
+// since the last resort [CoreError] is used if the lookuper has nothing to offer.
+// Whenever the code is not mapped and a fallback happens, it is reported through
+// the formatter's logger.
+func (f *Formatter) lookup(code Code) CoreError {
+	core := f.coreDataLookup.Lookup(code)
+	if core == nil {
+		core = lastResortCore(Unknown)
+	}
+
+	if code != InheritCode && core.GetInternalCode() != code {
+		f.reportFallback(code, core)
+	}
+
+	return core
+}
+
+// reportFallback logs that the requested code had no mapping, at warn level if
+// the logger supports it.
+func (f *Formatter) reportFallback(code Code, core CoreError) {
+	f.warn(errUnmappedCode,
+		MetadataRequestedCode, int(code),
+		MetadataErrorCode, int(core.GetInternalCode()),
+		MetadataIdentifier, core.GetIdentifier(),
+	)
+}
+
 // Error allows GeneralError to implement the error interface.
-// It uses the formatter template and different information of the GeneralError
-// to generate the error message.
+// It uses the formatter's renderer, which is the formatter template by default,
+// and different information of the GeneralError to generate the error message.
+// Check [WithRenderer] for more information.
 func (ge *GeneralError) Error() string {
-	var buf bytes.Buffer
-
-	data := ge.getTemplateData()
+	view := ge.View()
 
-	if err := ge.formatter.template.Execute(&buf, data); err != nil {
-		return fmt.Sprintf("failed to execute template: %s (original error: %s)", err.Error(), data.Message)
+	out, err := ge.formatter.renderer.Render(view)
+	if err != nil {
+		return fmt.Sprintf("failed to render error: %s (original error: %s)", err.Error(), view.Message)
 	}
 
-	return buf.String()
+	return ge.formatter.redactText(out)
 }
 
 // Metadata returns all the combined labels of the given GeneralError.
 func (ge *GeneralError) Metadata() map[string]string {
-	return ge.details.GetMetadata()
+	return ge.getDetails().GetMetadata()
+}
+
+// Code returns the [Code] of the error's core error.
+func (ge *GeneralError) Code() Code {
+	return ge.coreError.GetInternalCode()
+}
+
+// DefaultMessage returns the default message of the error's code. If the core
+// error implements [CoreMessageTemplate], the message is rendered using the
+// error's labels.
+func (ge *GeneralError) DefaultMessage() string {
+	ge.getDetails()
+
+	return ge.message
+}
+
+// Operation returns the chain of operations the error has passed through,
+// outermost first. e.g. "handler > service > repo".
+// It is empty if the error has never been annotated.
+func (ge *GeneralError) Operation() string {
+	return strings.Join(ge.operations, operationSeparator)
 }
 
 // Grpc is the method defined on GeneralError which returns the gRPC error.
@@ -158,37 +381,125 @@ func (ge *GeneralError) Grpc() error {
 	return GrpcError(ge)
 }
 
-func (ge *GeneralError) generateDetails(metadataKeyValues []any, defaultLabels map[string]string) {
-	metadata := make(map[string]string)
+// GrpcContext is the same as Grpc, but it localizes the error based on the context.
+// See [GrpcErrorContext] for more details.
+func (ge *GeneralError) GrpcContext(ctx context.Context) error {
+	return GrpcErrorContext(ctx, ge)
+}
+
+// addLabels adds the key values to the labels of the error and returns the added labels.
+func (ge *GeneralError) addLabels(metadataKeyValues []any) map[string]labelValue {
+	added := make(map[string]labelValue)
+	ge.formatter.parseKeyValues(metadataKeyValues, added)
 
-	for k, v := range defaultLabels {
-		metadata[k] = v
+	for k, v := range added {
+		ge.labels[k] = v
 	}
 
-	metadata[MetadataIdentifier] = ge.coreError.GetIdentifier()
-	metadata[MetadataErrorCode] = strconv.Itoa(int(ge.coreError.GetInternalCode()))
-	metadata[MetadataDefaultMessage] = ge.coreError.GetDefaultMessage()
-	metadata[MetadataOriginalError] = ge.originalError.Error()
+	return added
+}
+
+// getDetails returns the error details and generates them on the first call.
+// Lazy label values are evaluated at this point.
+func (ge *GeneralError) getDetails() *errdetails.ErrorInfo {
+	ge.detailsOnce.Do(ge.generateDetails)
+
+	return ge.details
+}
+
+func (ge *GeneralError) generateDetails() {
+	ge.metadata = ge.typedMetadata()
+	metadata := make(map[string]string, len(ge.metadata))
+
+	for k, v := range ge.metadata {
+		v = v.resolve()
+		encoded := ge.formatter.encodeValue(v)
+
+		redacted, keep, changed := ge.formatter.redactLabel(k, encoded)
+		if !keep {
+			delete(ge.metadata, k)
 
-	for index := 0; index < len(metadataKeyValues); index += 2 {
-		key, val, ok := ge.formatter.getStringifiedKeyValue(metadataKeyValues, index)
-		if !ok {
 			continue
 		}
 
-		metadata[key] = val
+		if changed {
+			v, encoded = stringLabelValue(redacted), redacted
+		}
+
+		ge.metadata[k] = v
+		metadata[k] = encoded
+	}
+
+	ge.labelStrings = metadata
+	ge.message = ge.formatter.redactText(ge.formatter.renderDefaultMessage(ge.coreError, metadata))
+
+	if name, ok := ge.formatter.systemKey(MetadataDefaultMessage); ok {
+		ge.metadata[name] = stringLabelValue(ge.message)
+		metadata[name] = ge.message
 	}
 
 	ge.details = &errdetails.ErrorInfo{
-		Reason:   strings.ReplaceAll(strings.ToUpper(ge.coreError.GetIdentifier()), " ", "_"),
-		Metadata: metadata,
+		Reason:   ge.formatter.reasonOf(ge.coreError),
+		Domain:   ge.Domain(),
+		Metadata: ge.formatter.convertKeys(metadata),
+	}
+}
+
+// reasonOf returns the reason of the error details of the core error.
+// Check [Formatter.reasonOf] for the reason that is actually sent.
+func reasonOf(core CoreError) string {
+	if corer, ok := core.(CoreReasonError); ok && corer.GetReason() != "" {
+		return corer.GetReason()
+	}
+
+	return reasonFromIdentifier(core.GetIdentifier())
+}
+
+// reasonFromIdentifier returns the reason of the error details of an identifier.
+func reasonFromIdentifier(identifier string) string {
+	return strings.ReplaceAll(strings.ToUpper(identifier), " ", "_")
+}
+
+// typedMetadata combines formatter's labels, error's labels and system labels
+// while keeping the label values typed. Labels never override system labels.
+// Check [WithReservedKeyPolicy] and [WithLabelConflictPolicy] for more information.
+func (ge *GeneralError) typedMetadata() map[string]labelValue {
+	metadata := ge.formatter.mergeLabels(ge.defaultLabels, ge.labels)
+
+	ge.setSystemLabel(metadata, MetadataIdentifier, ge.coreError.GetIdentifier())
+	ge.setSystemLabel(metadata, MetadataErrorCode, strconv.Itoa(int(ge.coreError.GetInternalCode())))
+	ge.setSystemLabel(metadata, MetadataDefaultMessage, ge.coreError.GetDefaultMessage())
+	ge.setSystemLabel(metadata, MetadataOriginalError, ge.originalError.Error())
+
+	if len(ge.operations) > 0 {
+		ge.setSystemLabel(metadata, MetadataOperation, ge.Operation())
+	}
+
+	if domain := ge.Domain(); domain != "" {
+		ge.setSystemLabel(metadata, MetadataDomain, domain)
 	}
+
+	if ge.fallback {
+		ge.setSystemLabel(metadata, MetadataRequestedCode, strconv.Itoa(int(ge.requestedCode)))
+	}
+
+	return metadata
 }
 
-func (ge *GeneralError) getTemplateData() tplData {
-	msg := ge.coreError.GetDefaultMessage()
+// setSystemLabel sets the system label under its name, unless it is disabled. Check [WithSystemKey].
+func (ge *GeneralError) setSystemLabel(metadata map[string]labelValue, key, value string) {
+	if name, ok := ge.formatter.systemKey(key); ok {
+		metadata[name] = stringLabelValue(value)
+	}
+}
+
+// View returns the information of the error that is passed to renderers.
+func (ge *GeneralError) View() ErrorView {
+	details := ge.getDetails()
+
+	msg := ge.message
 	if !errors.Is(ge.originalError, errNoOriginalError) {
-		msg = ge.originalError.Error()
+		msg = ge.formatter.redactText(ge.originalError.Error())
 	}
 
 	var grpcCode string
@@ -198,21 +509,27 @@ func (ge *GeneralError) getTemplateData() tplData {
 		grpcCode = strconv.Itoa(int(coreg.GetGRPCCode()))
 	}
 
-	return tplData{
+	return ErrorView{
 		Identifier:     ge.coreError.GetIdentifier(),
+		Domain:         ge.Domain(),
 		ErrorCode:      strconv.Itoa(int(ge.coreError.GetInternalCode())),
 		GrpcErrorCode:  grpcCode,
 		Message:        msg,
-		DefaultMessage: ge.coreError.GetDefaultMessage(),
-		Labels:         ge.details.GetMetadata(),
+		DefaultMessage: ge.message,
+		Operation:      ge.Operation(),
+		Labels:         ge.formatter.budgetMetadata(details.GetMetadata()),
+		Stack:          ge.stackTrace(),
+		systemKeys:     ge.formatter.outputSystemKeys(),
 	}
 }
 
-func (ge *GeneralError) log(logger logger, level LogLevel, metadata []any) {
+func (ge *GeneralError) log(logger logger, level LogLevel) {
 	if logger == nil || level == LogLevelOff {
 		return
 	}
 
+	metadata := ge.MetadataSlice()
+
 	// nolint: exhaustive
 	switch level {
 	case LogLevelTrace:
@@ -245,19 +562,25 @@ func (ge *GeneralError) log(logger logger, level LogLevel, metadata []any) {
 		l.Warn(ge.Error(), metadata...)
 	default:
 		logger.Error(
-			ge.originalError,
+			ge.loggedError(),
 			ge.Error(),
 			metadata...,
 		)
 	}
 }
 
+// MetadataSlice returns all the combined labels of the given GeneralError as a slice.
+// Even indexed elements are keys and odd indexed elements are values.
+// Unlike [GeneralError.Metadata], values keep their original type, which makes
+// the slice suitable for structured loggers.
 func (ge *GeneralError) MetadataSlice() []any {
-	s := make([]any, len(ge.details.GetMetadata())*2)
+	ge.getDetails()
+
+	s := make([]any, len(ge.metadata)*2)
 	index := 0
 
-	for k, v := range ge.details.GetMetadata() {
-		s[index], s[index+1] = k, v
+	for k, v := range ge.metadata {
+		s[index], s[index+1] = k, v.value
 		index += 2
 	}
 
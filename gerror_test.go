@@ -294,3 +294,73 @@ func (gcoreErr) GetDefaultMessage() string {
 func (gcoreErr) GetIdentifier() string {
 	return "custom"
 }
+
+func TestNewUnmappedCode(t *testing.T) {
+	t.Parallel()
+
+	l := &fallbackLogger{}
+	f := gerrors.NewFormatter(gerrors.WithLogger(l), gerrors.WithLookuper(nilLookuper{}))
+
+	err := f.New(nil, gerrors.NotFound)
+
+	if err.Code() != gerrors.Unknown || err.Metadata()[gerrors.MetadataRequestedCode] != "2" {
+		t.Errorf("expected fallback to unknown with requested code label, got %v", err.Metadata())
+	}
+
+	if len(l.warnings) != 1 || l.warnings[0][1] != int(gerrors.NotFound) {
+		t.Errorf("expected fallback to be reported once, got %v", l.warnings)
+	}
+
+	wrapped := f.New(err, gerrors.InheritCode)
+
+	if wrapped.Metadata()[gerrors.MetadataRequestedCode] != "2" || len(l.warnings) != 1 {
+		t.Errorf("expected inherited code to keep the requested code without reporting it again")
+	}
+
+	mapped := gerrors.NewFormatter(gerrors.WithLogger(l)).New(nil, gerrors.NotFound)
+
+	if _, ok := mapped.Metadata()[gerrors.MetadataRequestedCode]; ok {
+		t.Errorf("expected no requested code label for mapped codes")
+	}
+}
+
+type nilLookuper struct{}
+
+func (nilLookuper) Lookup(gerrors.Code) gerrors.CoreError {
+	return nil
+}
+
+type fallbackLogger struct {
+	warnings [][]any
+}
+
+func (l *fallbackLogger) Error(_ error, _ string, _ ...any) {}
+
+func (l *fallbackLogger) Warn(_ string, keyValues ...any) {
+	l.warnings = append(l.warnings, keyValues)
+}
+
+type okCore struct {
+	gcoreErr
+}
+
+func (okCore) GetGRPCCode() codes.Code {
+	return codes.OK
+}
+
+func TestGrpcErrorWithOKCode(t *testing.T) {
+	t.Parallel()
+
+	f := gerrors.NewFormatter(
+		gerrors.WithLookuper(gerrors.NewMapper(
+			gerrors.Code(100),
+			map[gerrors.Code]gerrors.CoreError{gerrors.Code(100): okCore{}},
+		)),
+	)
+
+	err := f.New(nil, gerrors.Code(100)).Grpc()
+
+	if err == nil || status.Code(err) != codes.Unknown {
+		t.Errorf("expected OK gRPC code to be reported as Unknown, got %v", err)
+	}
+}
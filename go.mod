@@ -1,17 +1,17 @@
 module github.com/seinshah/gerrors
 
-go 1.22
+go 1.23
 
 toolchain go1.23.5
 
 require (
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250122153221-138b5a5a4fd4
 	google.golang.org/grpc v1.70.0
+	google.golang.org/protobuf v1.36.3
 )
 
 require (
 	github.com/golang/protobuf v1.5.4 // indirect
 	golang.org/x/net v0.32.0 // indirect
 	golang.org/x/sys v0.28.0 // indirect
-	google.golang.org/protobuf v1.36.3 // indirect
 )
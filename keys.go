@@ -0,0 +1,211 @@
+package gerrors
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ReservedKeyPolicy decides what happens to labels whose key is reserved for a system key,
+// e.g. a label passed as "_error_code". Check [WithReservedKeyPolicy].
+type ReservedKeyPolicy int
+
+const (
+	// ReservedKeyReject drops the label and reports it through the formatter's logger,
+	// at warn level if the logger supports it.
+	ReservedKeyReject ReservedKeyPolicy = iota
+
+	// ReservedKeyRename keeps the label under a key prefixed with "label",
+	// e.g. "_error_code" becomes "label_error_code".
+	ReservedKeyRename
+
+	// ReservedKeyIgnore silently drops the label.
+	ReservedKeyIgnore
+)
+
+// LabelConflictPolicy decides what happens when a label of an error has the same key
+// as a label of the formatter. Check [WithLabelConflictPolicy].
+type LabelConflictPolicy int
+
+const (
+	// LabelConflictOverride keeps the error's label.
+	LabelConflictOverride LabelConflictPolicy = iota
+
+	// LabelConflictKeepFirst keeps the formatter's label.
+	LabelConflictKeepFirst
+
+	// LabelConflictSuffix keeps both labels by adding a numeric suffix to the key of
+	// the error's label, e.g. "user" becomes "user_2". Labels with the same value are kept once.
+	LabelConflictSuffix
+)
+
+// reservedKeyPrefix is prepended to reserved keys by [ReservedKeyRename].
+const reservedKeyPrefix = "label"
+
+// errReservedKey is reported whenever a label is dropped by [ReservedKeyReject].
+var errReservedKey = errors.New("label key is reserved for a system key, dropping the label")
+
+// WithReservedKeyPolicy sets what happens to labels whose key is reserved for a system key.
+// Both the original name of the system keys, e.g. [MetadataErrorCode], and the names they are
+// renamed to by [WithSystemKey] are reserved. It defaults to [ReservedKeyReject].
+func WithReservedKeyPolicy(policy ReservedKeyPolicy) FormatterOption {
+	return func(f *Formatter) {
+		f.reservedKeyPolicy = policy
+	}
+}
+
+// WithLabelConflictPolicy sets what happens when a label of an error, including the labels
+// it inherits from a wrapped [GeneralError], has the same key as a label of the formatter.
+// It defaults to [LabelConflictOverride].
+func WithLabelConflictPolicy(policy LabelConflictPolicy) FormatterOption {
+	return func(f *Formatter) {
+		f.labelConflictPolicy = policy
+	}
+}
+
+// WithSystemKey renames a system key, e.g. [MetadataErrorCode] to "error_code".
+// An empty name disables the system key, so it is never sent.
+// The key case set by [WithKeyCase] is applied to the new name as well.
+//
+//	f := NewFormatter(
+//		WithSystemKey(MetadataErrorCode, "error_code"),
+//		WithSystemKey(MetadataDefaultMessage, ""),
+//	)
+func WithSystemKey(key, name string) FormatterOption {
+	return func(f *Formatter) {
+		names := make(map[string]string, len(f.systemKeyNames)+1)
+
+		for k, v := range f.systemKeyNames {
+			names[k] = v
+		}
+
+		names[key] = name
+		f.systemKeyNames = names
+	}
+}
+
+// systemKey returns the name of the system key, and false if it is disabled.
+func (f *Formatter) systemKey(key string) (string, bool) {
+	name, ok := f.systemKeyNames[key]
+	if !ok {
+		return key, true
+	}
+
+	return name, name != ""
+}
+
+// outputSystemKeys returns the enabled system keys, as they are sent in the error details.
+func (f *Formatter) outputSystemKeys() []string {
+	keys := make([]string, 0, len(systemKeys))
+
+	for _, key := range systemKeys {
+		if name, ok := f.systemKey(key); ok {
+			keys = append(keys, f.keyCase.convert(name))
+		}
+	}
+
+	return keys
+}
+
+// isReservedKey reports whether the label key is reserved for a system key.
+func (f *Formatter) isReservedKey(key string) bool {
+	for _, systemKey := range systemKeys {
+		if key == systemKey {
+			return true
+		}
+
+		if name, ok := f.systemKey(systemKey); ok && key == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeLabels combines the formatter's labels and the error's labels based on the
+// label conflict and reserved key policies.
+func (f *Formatter) mergeLabels(defaultLabels, labels map[string]labelValue) map[string]labelValue {
+	metadata := make(map[string]labelValue, len(defaultLabels)+len(labels))
+
+	for k, v := range defaultLabels {
+		metadata[k] = v
+	}
+
+	for _, k := range sortedKeys(labels) {
+		if _, ok := metadata[k]; ok {
+			switch f.labelConflictPolicy {
+			case LabelConflictKeepFirst:
+				continue
+			case LabelConflictSuffix:
+				// Labels with the same value, e.g. inherited from an error of the same formatter,
+				// are not conflicting.
+				if !sameLabelValue(metadata[k], labels[k]) {
+					metadata[freeKey(metadata, k)] = labels[k]
+				}
+
+				continue
+			case LabelConflictOverride:
+			}
+		}
+
+		metadata[k] = labels[k]
+	}
+
+	for _, k := range sortedKeys(metadata) {
+		if !f.isReservedKey(k) {
+			continue
+		}
+
+		v := metadata[k]
+		delete(metadata, k)
+
+		switch f.reservedKeyPolicy {
+		case ReservedKeyRename:
+			renamed := reservedKeyPrefix + "_" + strings.TrimLeft(k, "_")
+			metadata[freeKey(metadata, renamed)] = v
+		case ReservedKeyReject:
+			f.reportReservedKey(k)
+		case ReservedKeyIgnore:
+		}
+	}
+
+	return metadata
+}
+
+// sameLabelValue reports whether both label values are equal.
+func sameLabelValue(a, b labelValue) bool {
+	return a.kind == b.kind && reflect.DeepEqual(a.value, b.value)
+}
+
+// reportReservedKey logs that a label was dropped, at warn level if the logger supports it.
+func (f *Formatter) reportReservedKey(key string) {
+	f.warn(errReservedKey, "key", key)
+}
+
+// freeKey returns the key, or the key with the lowest numeric suffix that is not used yet.
+func freeKey(metadata map[string]labelValue, key string) string {
+	if _, ok := metadata[key]; !ok {
+		return key
+	}
+
+	for i := 2; ; i++ {
+		candidate := key + "_" + strconv.Itoa(i)
+		if _, ok := metadata[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+func sortedKeys(labels map[string]labelValue) []string {
+	keys := make([]string, 0, len(labels))
+
+	for k := range labels {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,179 @@
+package gerrors_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+)
+
+func TestWithReservedKeyPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		opts             []gerrors.FormatterOption
+		expectedLabels   map[string]string
+		expectedWarnings int
+	}{
+		{
+			name: "reject",
+			opts: nil,
+			expectedLabels: map[string]string{
+				gerrors.MetadataErrorCode: "2",
+				"user":                    "john",
+			},
+			expectedWarnings: 1,
+		},
+		{
+			name: "rename",
+			opts: []gerrors.FormatterOption{gerrors.WithReservedKeyPolicy(gerrors.ReservedKeyRename)},
+			expectedLabels: map[string]string{
+				gerrors.MetadataErrorCode: "2",
+				"label_error_code":        "x",
+				"user":                    "john",
+			},
+			expectedWarnings: 0,
+		},
+		{
+			name: "ignore",
+			opts: []gerrors.FormatterOption{gerrors.WithReservedKeyPolicy(gerrors.ReservedKeyIgnore)},
+			expectedLabels: map[string]string{
+				gerrors.MetadataErrorCode: "2",
+				"user":                    "john",
+			},
+			expectedWarnings: 0,
+		},
+		{
+			name: "renamed system key is reserved",
+			opts: []gerrors.FormatterOption{gerrors.WithSystemKey(gerrors.MetadataErrorCode, "error_code")},
+			expectedLabels: map[string]string{
+				"error_code": "2",
+				"user":       "john",
+			},
+			expectedWarnings: 2,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := &fallbackLogger{warnings: nil}
+			f := gerrors.NewFormatter(append(tc.opts, gerrors.WithLogger(logger))...)
+
+			metadata := f.New(nil, gerrors.NotFound, "_error_code", "x", "error_code", "2", "user", "john").Metadata()
+
+			for k, v := range tc.expectedLabels {
+				if metadata[k] != v {
+					t.Errorf("expected label %s=%s, got %v", k, v, metadata)
+				}
+			}
+
+			if len(logger.warnings) != tc.expectedWarnings {
+				t.Errorf("expected %d warnings, got %v", tc.expectedWarnings, logger.warnings)
+			}
+		})
+	}
+}
+
+func TestWithLabelConflictPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		policy         gerrors.LabelConflictPolicy
+		expectedLabels map[string]string
+	}{
+		{
+			name:           "override",
+			policy:         gerrors.LabelConflictOverride,
+			expectedLabels: map[string]string{"service": "billing"},
+		},
+		{
+			name:           "keep first",
+			policy:         gerrors.LabelConflictKeepFirst,
+			expectedLabels: map[string]string{"service": "api"},
+		},
+		{
+			name:           "suffix",
+			policy:         gerrors.LabelConflictSuffix,
+			expectedLabels: map[string]string{"service": "api", "service_2": "billing"},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := gerrors.NewFormatter(gerrors.WithLabels("service", "api"), gerrors.WithLabelConflictPolicy(tc.policy))
+			metadata := f.New(nil, gerrors.NotFound, "service", "billing").Metadata()
+
+			for k, v := range tc.expectedLabels {
+				if metadata[k] != v {
+					t.Errorf("expected label %s=%s, got %v", k, v, metadata)
+				}
+			}
+		})
+	}
+}
+
+func TestLabelConflictSuffixRewrap(t *testing.T) {
+	t.Parallel()
+
+	f := gerrors.NewFormatter(gerrors.WithLabels("svc", "a"), gerrors.WithLabelConflictPolicy(gerrors.LabelConflictSuffix))
+
+	e1 := f.New(nil, gerrors.NotFound)
+	e2 := f.New(e1, gerrors.InheritCode)
+	e3 := f.New(e2, gerrors.InheritCode, "user", "john")
+
+	metadata := e3.Metadata()
+
+	if metadata["svc"] != "a" || metadata["user"] != "john" {
+		t.Errorf("expected formatter labels to be kept, got %v", metadata)
+	}
+
+	if _, ok := metadata["svc_2"]; ok {
+		t.Errorf("expected re-wrapping with the same formatter not to conflict, got %v", metadata)
+	}
+
+	other := gerrors.NewFormatter(gerrors.WithLabels("svc", "b"), gerrors.WithLabelConflictPolicy(gerrors.LabelConflictSuffix))
+
+	if metadata := other.New(e1, gerrors.InheritCode).Metadata(); metadata["svc"] != "b" || metadata["svc_2"] != "a" {
+		t.Errorf("expected labels of another formatter to conflict, got %v", metadata)
+	}
+}
+
+func TestWithSystemKey(t *testing.T) {
+	t.Parallel()
+
+	f := gerrors.NewFormatter(
+		gerrors.WithSystemKey(gerrors.MetadataErrorCode, "error_code"),
+		gerrors.WithSystemKey(gerrors.MetadataDefaultMessage, ""),
+	)
+	err := f.New(nil, gerrors.NotFound, "user", "john")
+	metadata := err.Metadata()
+
+	if metadata["error_code"] != "2" {
+		t.Errorf("expected renamed error code, got %v", metadata)
+	}
+
+	for _, key := range []string{gerrors.MetadataErrorCode, gerrors.MetadataDefaultMessage} {
+		if _, ok := metadata[key]; ok {
+			t.Errorf("expected %s not to be sent, got %v", key, metadata)
+		}
+	}
+
+	if err.DefaultMessage() == "" {
+		t.Errorf("expected default message to be available")
+	}
+
+	out, _ := err.RenderWith(gerrors.JSONRenderer{})
+	if !strings.Contains(out, `"labels":{"user":"john"}`) {
+		t.Errorf("expected renamed system key to be left out of labels, got %s", out)
+	}
+}
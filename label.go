@@ -0,0 +1,162 @@
+package gerrors
+
+import (
+	"reflect"
+	"strings"
+)
+
+const (
+	// labelTag is the struct tag that is read by [LabelsFrom].
+	labelTag = "gerr"
+
+	// secretValueReplacement replaces the value of struct fields that are
+	// tagged as secret.
+	secretValueReplacement = "[REDACTED]"
+)
+
+// Labels is a list of key values that can be passed as a single element
+// wherever key values are accepted. e.g. [Formatter.New], [Formatter.AddLabels],
+// [WithLabels], or [Wrap]. It is expanded in place to its key values.
+//
+//	f.New(err, gerrors.NotFound, gerrors.LabelsFrom(req), "attempt", 2)
+type Labels []any
+
+// lazyValue is a label value that is evaluated only when it is needed.
+type lazyValue func() any
+
+// Lazy returns a label value that is evaluated only when the error is
+// rendered, logged or encoded. It is useful for expensive labels in hot paths
+// where the error might be dropped without being used.
+// A lazy value is evaluated at most once per error. If it is used as a formatter
+// label, it is evaluated once for every error that uses it.
+func Lazy(fn func() any) any {
+	return lazyValue(fn)
+}
+
+// LabelsFrom reads the fields of a struct, or a pointer to a struct, that are
+// tagged with "gerr" and returns them as [Labels].
+// The tag value is the label key followed by optional comma-separated flags:
+//
+//   - omitempty: the field is ignored if it holds the zero value.
+//
+//   - secret: the field value is replaced with "[REDACTED]".
+//
+// Fields tagged with "-", untagged fields and unexported fields are ignored.
+// Untagged embedded structs, and non-nil pointers to structs, are read as if their
+// fields belonged to the parent.
+//
+//	type GetUserRequest struct {
+//		UserID   string `gerr:"user_id"`
+//		Region   string `gerr:"region,omitempty"`
+//		Password string `gerr:"password,secret"`
+//	}
+func LabelsFrom(v any) Labels {
+	rv := reflect.ValueOf(v)
+
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return Labels{}
+		}
+
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return Labels{}
+	}
+
+	return appendStructLabels(Labels{}, rv)
+}
+
+// embeddedStruct returns the struct of an embedded field, which is either a struct
+// or a non-nil pointer to a struct.
+func embeddedStruct(field reflect.StructField, fv reflect.Value) (reflect.Value, bool) {
+	if !field.Anonymous {
+		return fv, false
+	}
+
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return fv, false
+		}
+
+		fv = fv.Elem()
+	}
+
+	return fv, fv.Kind() == reflect.Struct
+}
+
+func appendStructLabels(labels Labels, rv reflect.Value) Labels {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, tagged := field.Tag.Lookup(labelTag)
+
+		if !tagged {
+			if embedded, ok := embeddedStruct(field, rv.Field(i)); ok {
+				labels = appendStructLabels(labels, embedded)
+			}
+
+			continue
+		}
+
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		name, flags, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		var omitEmpty, secret bool
+
+		for _, flag := range strings.Split(flags, ",") {
+			switch flag {
+			case "omitempty":
+				omitEmpty = true
+			case "secret":
+				secret = true
+			}
+		}
+
+		fv := rv.Field(i)
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		if secret {
+			labels = append(labels, name, secretValueReplacement)
+
+			continue
+		}
+
+		labels = append(labels, name, fv.Interface())
+	}
+
+	return labels
+}
+
+// flattenKeyValues expands every [Labels] element at a key position
+// to its key values.
+func flattenKeyValues(keyValues []any) []any {
+	flat := make([]any, 0, len(keyValues))
+
+	for i := 0; i < len(keyValues); i++ {
+		if labels, ok := keyValues[i].(Labels); ok {
+			flat = append(flat, flattenKeyValues(labels)...)
+
+			continue
+		}
+
+		flat = append(flat, keyValues[i])
+
+		if i+1 < len(keyValues) {
+			flat = append(flat, keyValues[i+1])
+			i++
+		}
+	}
+
+	return flat
+}
@@ -0,0 +1,122 @@
+package gerrors_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+)
+
+type requestMeta struct {
+	TraceID string `gerr:"trace_id"`
+}
+
+type getUserRequest struct {
+	requestMeta
+	UserID   string `gerr:"user_id"`
+	Region   string `gerr:"region,omitempty"`
+	Password string `gerr:"password,secret"`
+	Internal string `gerr:"-"`
+	Limit    int    `gerr:",omitempty"`
+	Ignored  string
+}
+
+type tenantMeta struct {
+	TenantID string `gerr:"tenant_id"`
+}
+
+type listUsersRequest struct {
+	*requestMeta
+	*tenantMeta
+	PageSize int `gerr:"page_size"`
+}
+
+func TestLabelsFromEmbeddedPointers(t *testing.T) {
+	t.Parallel()
+
+	req := listUsersRequest{requestMeta: &requestMeta{TraceID: "abc"}, tenantMeta: nil, PageSize: 20}
+	err := gerrors.NewFormatter().New(nil, gerrors.NotFound, gerrors.LabelsFrom(req))
+
+	expected := map[string]string{
+		"trace_id":  "abc",
+		"page_size": "20",
+	}
+
+	for k, v := range expected {
+		if err.Metadata()[k] != v {
+			t.Errorf("expected %s to be %q, got %q", k, v, err.Metadata()[k])
+		}
+	}
+
+	if _, ok := err.Metadata()["tenant_id"]; ok {
+		t.Errorf("expected nil embedded pointers to be ignored")
+	}
+}
+
+func TestLabelsFrom(t *testing.T) {
+	t.Parallel()
+
+	req := &getUserRequest{
+		requestMeta: requestMeta{TraceID: "abc"},
+		UserID:      "u1",
+		Password:    "pass",
+		Internal:    "internal",
+		Limit:       10,
+		Ignored:     "ignored",
+	}
+
+	f := gerrors.NewFormatter().AddLabels(gerrors.LabelsFrom(requestMeta{TraceID: "xyz"}))
+	err := f.New(nil, gerrors.NotFound, gerrors.LabelsFrom(req), "attempt", 2)
+
+	expected := map[string]string{
+		"trace_id": "abc",
+		"user_id":  "u1",
+		"password": "[REDACTED]",
+		"Limit":    "10",
+		"attempt":  "2",
+	}
+
+	for k, v := range expected {
+		if err.Metadata()[k] != v {
+			t.Errorf("expected %s to be %q, got %q", k, v, err.Metadata()[k])
+		}
+	}
+
+	for _, k := range []string{"region", "Internal", "Ignored"} {
+		if _, ok := err.Metadata()[k]; ok {
+			t.Errorf("expected %s to be ignored", k)
+		}
+	}
+
+	if len(gerrors.LabelsFrom((*getUserRequest)(nil))) != 0 || len(gerrors.LabelsFrom("value")) != 0 {
+		t.Errorf("expected no labels from nil pointer or non-struct values")
+	}
+}
+
+func TestLazyLabels(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	lazy := gerrors.Lazy(func() any {
+		calls++
+
+		return 42
+	})
+
+	err := gerrors.NewFormatter().New(errors.New("error"), gerrors.Internal, "expensive", lazy)
+
+	if calls != 0 {
+		t.Fatalf("expected lazy value not to be evaluated before use, got %d calls", calls)
+	}
+
+	if err.Metadata()["expensive"] != "42" {
+		t.Errorf("expected lazy value to be evaluated, got %q", err.Metadata()["expensive"])
+	}
+
+	_ = err.Error()
+	_ = err.MetadataSlice()
+
+	if calls != 1 {
+		t.Errorf("expected lazy value to be evaluated once, got %d calls", calls)
+	}
+}
@@ -0,0 +1,338 @@
+package gerrors
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// LabelType is the type of a label value declared by a [LabelSpec].
+// Labels are always sent as strings, so the type describes the Go value the
+// label is created with and, in generated schemas, how its string is formatted.
+type LabelType string
+
+const (
+	// LabelTypeAny accepts values of any kind. It is the default type of a label.
+	LabelTypeAny LabelType = ""
+
+	// LabelTypeString accepts strings and values implementing [fmt.Stringer].
+	LabelTypeString LabelType = "string"
+
+	// LabelTypeInteger accepts signed and unsigned integers.
+	LabelTypeInteger LabelType = "integer"
+
+	// LabelTypeNumber accepts integers and floating point numbers.
+	LabelTypeNumber LabelType = "number"
+
+	// LabelTypeBoolean accepts booleans.
+	LabelTypeBoolean LabelType = "boolean"
+
+	// LabelTypeDuration accepts [time.Duration] values.
+	LabelTypeDuration LabelType = "duration"
+
+	// LabelTypeTime accepts [time.Time] values.
+	LabelTypeTime LabelType = "time"
+)
+
+// LabelValidation decides how the labels of new errors are checked against the
+// label schema of their code. Check [WithLabelValidation].
+type LabelValidation int
+
+const (
+	// LabelValidationOff does not check the labels.
+	LabelValidationOff LabelValidation = iota
+
+	// LabelValidationLog reports violations through the formatter's logger,
+	// at warn level if the logger supports it.
+	LabelValidationLog
+
+	// LabelValidationPanic panics with the violations, wrapping [ErrLabelSchemaViolation].
+	// It is meant for development and tests, so that violations fail loudly.
+	LabelValidationPanic
+)
+
+var (
+	// ErrLabelSchemaViolation is reported whenever the labels of an error do not match
+	// the label schema of its code.
+	ErrLabelSchemaViolation = errors.New("labels violate the label schema")
+
+	// ErrInvalidLabelSchema is returned whenever a label schema fails validation.
+	ErrInvalidLabelSchema = errors.New("invalid label schema")
+)
+
+// LabelSpec declares a single label of an error code.
+type LabelSpec struct {
+	// Key is the key of the label, as it is passed to [Formatter.New].
+	Key string `json:"key"`
+
+	// Type is the type of the label value. It defaults to [LabelTypeAny].
+	Type LabelType `json:"type,omitempty"`
+
+	// Required means every error of the code should have the label.
+	Required bool `json:"required,omitempty"`
+
+	// Description explains the label in the generated documents.
+	Description string `json:"description,omitempty"`
+}
+
+// LabelSchema declares the labels of an error code: which ones are required,
+// which ones are allowed and what type their values have. e.g. a "not found" code
+// can require resource_type and resource_id, and a "threshold" code can require
+// limit to be an integer.
+// Labels that are not declared are rejected, unless AllowUndeclared is set.
+// Labels of the formatter and labels inherited from a wrapped error are never rejected
+// for being undeclared, but they count towards the required labels.
+type LabelSchema struct {
+	Labels          []LabelSpec
+	AllowUndeclared bool
+}
+
+// WithLabelValidation sets how the labels of the errors created by [Formatter.New] and
+// [Formatter.NewWithLogLevel] are checked against the label schema of their code.
+// Only codes whose [CoreError] implements [CoreLabelSchema] are checked.
+// It defaults to [LabelValidationOff].
+//
+//	f := NewFormatter(WithLabelValidation(LabelValidationPanic))
+func WithLabelValidation(mode LabelValidation) FormatterOption {
+	return func(f *Formatter) {
+		f.labelValidation = mode
+	}
+}
+
+// Validate checks the schema itself and reports every violation, joined together.
+// A valid schema has non-empty and unique keys and known types.
+func (s *LabelSchema) Validate() error {
+	var errs []error
+
+	seen := make(map[string]bool, len(s.Labels))
+
+	for _, spec := range s.Labels {
+		switch {
+		case spec.Key == "":
+			errs = append(errs, fmt.Errorf("%w: label has no key", ErrInvalidLabelSchema))
+		case seen[spec.Key]:
+			errs = append(errs, fmt.Errorf("%w: duplicate label %q", ErrInvalidLabelSchema, spec.Key))
+		}
+
+		seen[spec.Key] = true
+
+		if !spec.Type.valid() {
+			errs = append(errs, fmt.Errorf("%w: label %q has unknown type %q",
+				ErrInvalidLabelSchema, spec.Key, spec.Type))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Lookup returns the declaration of the label, and false if it is not declared.
+func (s *LabelSchema) Lookup(key string) (LabelSpec, bool) {
+	for _, spec := range s.Labels {
+		if spec.Key == key {
+			return spec, true
+		}
+	}
+
+	return LabelSpec{}, false
+}
+
+// check returns the violations of the labels of an error. labels are all the labels
+// of the error, and added are the ones that are passed when the error is created.
+func (s *LabelSchema) check(labels, added map[string]labelValue) []string {
+	var violations []string
+
+	for _, spec := range s.Labels {
+		value, ok := labels[spec.Key]
+		if !ok {
+			if spec.Required {
+				violations = append(violations, fmt.Sprintf("missing required label %q", spec.Key))
+			}
+
+			continue
+		}
+
+		if _, lazy := value.value.(lazyValue); lazy {
+			// Lazy values are only evaluated when the error is sent, so their type is not checked.
+			continue
+		}
+
+		if !spec.Type.accepts(value.kind) {
+			violations = append(violations, fmt.Sprintf("label %q should be of type %s", spec.Key, spec.Type))
+		}
+	}
+
+	if s.AllowUndeclared {
+		return violations
+	}
+
+	for _, key := range sortedKeys(added) {
+		if _, ok := s.Lookup(key); !ok {
+			violations = append(violations, fmt.Sprintf("label %q is not declared", key))
+		}
+	}
+
+	return violations
+}
+
+// valid reports whether the type is known.
+func (t LabelType) valid() bool {
+	switch t {
+	case LabelTypeAny, LabelTypeString, LabelTypeInteger, LabelTypeNumber,
+		LabelTypeBoolean, LabelTypeDuration, LabelTypeTime:
+		return true
+	default:
+		return false
+	}
+}
+
+// accepts reports whether values of the kind are of the type.
+func (t LabelType) accepts(kind ValueKind) bool {
+	switch t {
+	case LabelTypeAny:
+		return true
+	case LabelTypeString:
+		return kind == KindString || kind == KindStringer
+	case LabelTypeInteger:
+		return kind == KindInt || kind == KindUint
+	case LabelTypeNumber:
+		return kind == KindInt || kind == KindUint || kind == KindFloat
+	case LabelTypeBoolean:
+		return kind == KindBool
+	case LabelTypeDuration:
+		return kind == KindDuration
+	case LabelTypeTime:
+		return kind == KindTime
+	default:
+		return false
+	}
+}
+
+// example returns an example value of the type for the label with the given key,
+// used by the generated documents. Values are encoded by the formatter like any other
+// label, so the examples match the schemas of the labels.
+func (t LabelType) example(key string) any {
+	// nolint: exhaustive
+	switch t {
+	case LabelTypeInteger:
+		return 42
+	case LabelTypeNumber:
+		return 1.5
+	case LabelTypeBoolean:
+		return true
+	case LabelTypeDuration:
+		return 30 * time.Second
+	case LabelTypeTime:
+		return time.Date(2024, time.January, 2, 15, 4, 5, 0, time.UTC)
+	default:
+		return "<" + key + ">"
+	}
+}
+
+// exampleLabels returns the key-value pairs of example values for the labels.
+func exampleLabels(specs []LabelSpec) []any {
+	keyValues := make([]any, 0, len(specs)*2)
+
+	for _, spec := range specs {
+		keyValues = append(keyValues, spec.Key, spec.Type.example(spec.Key))
+	}
+
+	return keyValues
+}
+
+// validateLabels checks the labels of the error against the label schema of its code,
+// based on the formatter's label validation mode. added are the labels that were passed
+// when the error was created. Check [WithLabelValidation].
+func (f *Formatter) validateLabels(ge *GeneralError, added map[string]labelValue) {
+	if f.labelValidation == LabelValidationOff {
+		return
+	}
+
+	schema := labelSchemaOf(ge.coreError)
+	if schema == nil {
+		return
+	}
+
+	labels := make(map[string]labelValue, len(ge.defaultLabels)+len(ge.labels))
+
+	for k, v := range ge.defaultLabels {
+		labels[k] = v
+	}
+
+	for k, v := range ge.labels {
+		labels[k] = v
+	}
+
+	violations := schema.check(labels, added)
+	if len(violations) == 0 {
+		return
+	}
+
+	core := ge.coreError
+
+	if f.labelValidation == LabelValidationPanic {
+		errs := make([]error, 0, len(violations))
+
+		for _, violation := range violations {
+			errs = append(errs, fmt.Errorf("%w: %s(%d): %s",
+				ErrLabelSchemaViolation, core.GetIdentifier(), core.GetInternalCode(), violation))
+		}
+
+		panic(errors.Join(errs...))
+	}
+
+	f.warn(ErrLabelSchemaViolation,
+		MetadataErrorCode, int(core.GetInternalCode()),
+		MetadataIdentifier, core.GetIdentifier(),
+		"violations", violations,
+	)
+}
+
+// labelSchemaOf returns the label schema of the core, or nil if it has none.
+func labelSchemaOf(core CoreError) *LabelSchema {
+	if corel, ok := core.(CoreLabelSchema); ok {
+		return corel.GetLabelSchema()
+	}
+
+	return nil
+}
+
+// labelSpecsOf returns the labels of the core that are used by the generated documents:
+// the labels referenced by its message template, which are required strings unless
+// declared otherwise, followed by the rest of the labels declared by its label schema.
+func labelSpecsOf(core CoreError) []LabelSpec {
+	var (
+		specs  []LabelSpec
+		schema = labelSchemaOf(core)
+	)
+
+	if corem, ok := core.(CoreMessageTemplate); ok {
+		for _, field := range getMessageTemplate(corem.GetMessageTemplate()).fields {
+			if slices.ContainsFunc(specs, func(spec LabelSpec) bool { return spec.Key == field }) {
+				continue
+			}
+
+			spec := LabelSpec{Key: field, Type: LabelTypeString, Required: true, Description: ""}
+
+			if schema != nil {
+				if declared, ok := schema.Lookup(field); ok {
+					spec = declared
+				}
+			}
+
+			specs = append(specs, spec)
+		}
+	}
+
+	if schema == nil {
+		return specs
+	}
+
+	for _, spec := range schema.Labels {
+		if !slices.ContainsFunc(specs, func(s LabelSpec) bool { return s.Key == spec.Key }) {
+			specs = append(specs, spec)
+		}
+	}
+
+	return specs
+}
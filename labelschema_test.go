@@ -0,0 +1,315 @@
+package gerrors_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+)
+
+const (
+	schemaNotFound  gerrors.Code = 100
+	schemaThreshold gerrors.Code = 101
+)
+
+func labelSchemaCatalog() *gerrors.Catalog {
+	return &gerrors.Catalog{
+		UnknownCode: gerrors.Unknown,
+		Domain:      "",
+		Entries: []*gerrors.CatalogEntry{
+			{Code: gerrors.Unknown, Identifier: "unknown", Message: "unknown error"},
+			{
+				Code:            schemaNotFound,
+				Identifier:      "not-found",
+				Message:         "resource was not found",
+				MessageTemplate: "{{.resource_type}} {{.resource_id}} was not found",
+				Labels: []gerrors.LabelSpec{
+					{Key: "resource_type", Type: gerrors.LabelTypeString, Required: true, Description: "kind of resource"},
+					{Key: "resource_id", Type: gerrors.LabelTypeAny, Required: true, Description: ""},
+				},
+				AllowUndeclaredLabels: true,
+			},
+			{
+				Code:       schemaThreshold,
+				Identifier: "threshold",
+				Message:    "threshold exceeded",
+				Labels: []gerrors.LabelSpec{
+					{Key: "limit", Type: gerrors.LabelTypeInteger, Required: true, Description: "maximum allowed value"},
+					{Key: "ratio", Type: gerrors.LabelTypeNumber, Required: false, Description: ""},
+				},
+			},
+		},
+	}
+}
+
+func TestWithLabelValidation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		code               gerrors.Code
+		labels             []any
+		formatterLabels    []any
+		expectedViolations int
+	}{
+		{
+			name:               "valid labels",
+			code:               schemaThreshold,
+			labels:             []any{"limit", uint8(10), "ratio", 0.5},
+			expectedViolations: 0,
+		},
+		{
+			name:               "missing required label",
+			code:               schemaNotFound,
+			labels:             []any{"resource_type", "user"},
+			expectedViolations: 1,
+		},
+		{
+			name:               "required label from the formatter",
+			code:               schemaNotFound,
+			labels:             []any{"resource_id", 42},
+			formatterLabels:    []any{"resource_type", "user"},
+			expectedViolations: 0,
+		},
+		{
+			name:               "wrong type",
+			code:               schemaThreshold,
+			labels:             []any{"limit", "ten", "ratio", true},
+			expectedViolations: 2,
+		},
+		{
+			name:               "undeclared label",
+			code:               schemaThreshold,
+			labels:             []any{"limit", 10, "user", "john"},
+			expectedViolations: 1,
+		},
+		{
+			name:               "undeclared formatter label",
+			code:               schemaThreshold,
+			labels:             []any{"limit", 10},
+			formatterLabels:    []any{"request_id", "r1"},
+			expectedViolations: 0,
+		},
+		{
+			name:               "undeclared labels are allowed",
+			code:               schemaNotFound,
+			labels:             []any{"resource_type", "user", "resource_id", 42, "user", "john"},
+			expectedViolations: 0,
+		},
+		{
+			name:               "lazy values are not type checked",
+			code:               schemaThreshold,
+			labels:             []any{"limit", gerrors.Lazy(func() any { return "ten" })},
+			expectedViolations: 0,
+		},
+		{
+			name:               "code without schema",
+			code:               gerrors.Unknown,
+			labels:             []any{"user", "john"},
+			expectedViolations: 0,
+		},
+	}
+
+	lookuper := labelSchemaCatalog().Mapper()
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			l := &fallbackLogger{}
+			f := gerrors.NewFormatter(
+				gerrors.WithLogger(l),
+				gerrors.WithLookuper(lookuper),
+				gerrors.WithLabelValidation(gerrors.LabelValidationLog),
+			).AddLabels(tc.formatterLabels...)
+
+			f.New(nil, tc.code, tc.labels...)
+
+			violations := 0
+			if len(l.warnings) > 0 {
+				violations = len(l.warnings[0][5].([]string))
+			}
+
+			if violations != tc.expectedViolations || len(l.warnings) > 1 {
+				t.Errorf("expected %d violations, got %v", tc.expectedViolations, l.warnings)
+			}
+		})
+	}
+}
+
+func TestWithLabelValidationModes(t *testing.T) {
+	t.Parallel()
+
+	lookuper := labelSchemaCatalog().Mapper()
+
+	l := &fallbackLogger{}
+	off := gerrors.NewFormatter(gerrors.WithLogger(l), gerrors.WithLookuper(lookuper))
+	off.NewWithLogLevel(nil, schemaThreshold, gerrors.LogLevelOff)
+
+	if len(l.warnings) != 0 {
+		t.Errorf("expected no validation by default, got %v", l.warnings)
+	}
+
+	strict := gerrors.NewFormatter(
+		gerrors.WithLookuper(lookuper),
+		gerrors.WithLabelValidation(gerrors.LabelValidationPanic),
+	)
+
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !errors.Is(err, gerrors.ErrLabelSchemaViolation) {
+			t.Fatalf("expected a label schema violation panic, got %v", err)
+		}
+
+		if !strings.Contains(err.Error(), `missing required label "limit"`) {
+			t.Errorf("expected the violation to name the label, got %v", err)
+		}
+	}()
+
+	strict.NewWithLogLevel(nil, schemaThreshold, gerrors.LogLevelOff)
+	t.Error("expected a panic")
+}
+
+func TestLabelSchemaValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		entry    *gerrors.CatalogEntry
+		expected int
+	}{
+		{
+			name: "valid",
+			entry: &gerrors.CatalogEntry{
+				Code: 100, Identifier: "threshold", MessageTemplate: "limit is {{.limit}}",
+				Labels: []gerrors.LabelSpec{{Key: "limit", Type: gerrors.LabelTypeInteger}},
+			},
+			expected: 0,
+		},
+		{
+			name: "invalid labels",
+			entry: &gerrors.CatalogEntry{
+				Code: 100, Identifier: "threshold",
+				Labels: []gerrors.LabelSpec{{Key: ""}, {Key: "limit", Type: "int"}, {Key: "limit"}},
+			},
+			expected: 1,
+		},
+		{
+			name: "undeclared template label",
+			entry: &gerrors.CatalogEntry{
+				Code: 100, Identifier: "threshold", MessageTemplate: "{{.limit}} of {{.kind}}",
+				Labels: []gerrors.LabelSpec{{Key: "limit"}},
+			},
+			expected: 1,
+		},
+		{
+			name: "undeclared template label is allowed",
+			entry: &gerrors.CatalogEntry{
+				Code: 100, Identifier: "threshold", MessageTemplate: "{{.limit}} of {{.kind}}",
+				Labels:                []gerrors.LabelSpec{{Key: "limit"}},
+				AllowUndeclaredLabels: true,
+			},
+			expected: 0,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &gerrors.Catalog{
+				UnknownCode: gerrors.Unknown,
+				Entries: []*gerrors.CatalogEntry{
+					{Code: gerrors.Unknown, Identifier: "unknown"},
+					tc.entry,
+				},
+			}
+
+			err := c.Validate()
+
+			var joined interface{ Unwrap() []error }
+
+			violations := 0
+			if errors.As(err, &joined) {
+				violations = len(joined.Unwrap())
+			}
+
+			if violations != tc.expected || (err != nil && !errors.Is(err, gerrors.ErrInvalidCatalog)) {
+				t.Errorf("expected %d violations, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestLabelSchemaGenerators(t *testing.T) {
+	t.Parallel()
+
+	lookuper := labelSchemaCatalog().Mapper()
+
+	testCases := []struct {
+		name     string
+		write    func(*bytes.Buffer) error
+		expected []string
+	}{
+		{
+			name: "markdown docs",
+			write: func(buf *bytes.Buffer) error {
+				return gerrors.WriteDocs(buf, lookuper, gerrors.DocsMarkdown)
+			},
+			expected: []string{
+				"| `resource_type` | string | yes | kind of resource |",
+				"| `limit` | integer | yes | maximum allowed value |",
+				"| `ratio` | number | no |  |",
+			},
+		},
+		{
+			name: "typescript",
+			write: func(buf *bytes.Buffer) error {
+				return gerrors.WriteTypeScript(buf, lookuper)
+			},
+			expected: []string{
+				`"resource_type": string;`,
+				"/** maximum allowed value Type: integer. */\n  \"limit\": string;",
+				`"ratio"?: string;`,
+			},
+		},
+		{
+			name: "openapi",
+			write: func(buf *bytes.Buffer) error {
+				return gerrors.WriteOpenAPI(buf, lookuper)
+			},
+			expected: []string{
+				`"pattern": "^-?[0-9]+$"`,
+				`"description": "maximum allowed value"`,
+				`"limit": "42"`,
+				`"ratio": "1.5"`,
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			if err := tc.write(&buf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, expected := range tc.expected {
+				if !strings.Contains(buf.String(), expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, buf.String())
+				}
+			}
+		})
+	}
+}
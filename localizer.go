@@ -0,0 +1,324 @@
+package gerrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// acceptLanguageKey is the HTTP header and gRPC metadata key that holds
+	// the preferred locales of the requester.
+	acceptLanguageKey = "accept-language"
+
+	// catalogExtension is the extension of message catalog files loaded by [Localizer.LoadFS].
+	catalogExtension = ".json"
+)
+
+// errInvalidCatalogCode is returned when a message catalog has a key that is not a [Code].
+var errInvalidCatalogCode = errors.New("message catalog key is not a valid code")
+
+// localeContextKey is the context key for storing the requester's preferred locales.
+type localeContextKey struct{}
+
+// Localizer holds message catalogs keyed by locale and [Code].
+// Messages can use label keys as template fields, the same way as [CoreMessageTemplate].
+// A localizer should be fully loaded before it is passed to [WithLocalizer], since it
+// is not safe to add messages to it while errors are being localized.
+type Localizer struct {
+	defaultLocale string
+	locales       map[string]string
+	catalogs      map[string]map[Code]string
+}
+
+// NewLocalizer creates an empty localizer. defaultLocale is used whenever
+// none of the requester's preferred locales have a message for the code.
+func NewLocalizer(defaultLocale string) *Localizer {
+	return &Localizer{
+		defaultLocale: normalizeLocale(defaultLocale),
+		locales:       make(map[string]string),
+		catalogs:      make(map[string]map[Code]string),
+	}
+}
+
+// WithLocalizer attaches a localizer to the formatter. It enables
+// [GeneralError.LocalizedMessage], adds a localized message to gRPC errors
+// created by [GrpcErrorContext] and to views created by [GeneralError.ViewContext].
+func WithLocalizer(l *Localizer) FormatterOption {
+	return func(f *Formatter) {
+		f.localizer = l
+	}
+}
+
+// AddMessages adds the messages of the locale to the localizer.
+// Existing messages of the locale with the same code are overridden.
+func (l *Localizer) AddMessages(locale string, messages map[Code]string) *Localizer {
+	key := normalizeLocale(locale)
+
+	if _, ok := l.catalogs[key]; !ok {
+		l.catalogs[key] = make(map[Code]string)
+		l.locales[key] = locale
+	}
+
+	for code, msg := range messages {
+		l.catalogs[key][code] = msg
+	}
+
+	return l
+}
+
+// LoadJSON reads a JSON object whose keys are codes and whose values are
+// messages and adds them to the localizer for the locale.
+//
+//	{"2": "Kein Eintrag gefunden", "100": "Benutzer {{.user_id}} nicht gefunden"}
+func (l *Localizer) LoadJSON(locale string, r io.Reader) error {
+	var raw map[string]string
+
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return fmt.Errorf("failed to decode %s messages: %w", locale, err)
+	}
+
+	messages := make(map[Code]string, len(raw))
+
+	for key, msg := range raw {
+		code, err := strconv.Atoi(key)
+		if err != nil {
+			return fmt.Errorf("%w: %q in %s messages", errInvalidCatalogCode, key, locale)
+		}
+
+		messages[Code(code)] = msg
+	}
+
+	l.AddMessages(locale, messages)
+
+	return nil
+}
+
+// LoadFS loads every "<locale>.json" file in the directory of the file system,
+// e.g. an [embed.FS], using [Localizer.LoadJSON].
+//
+//	//go:embed locales/*.json
+//	var locales embed.FS
+//
+//	err := localizer.LoadFS(locales, "locales")
+func (l *Localizer) LoadFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*"+catalogExtension))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := l.loadFile(fsys, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *Localizer) loadFile(fsys fs.FS, file string) error {
+	f, err := fsys.Open(file)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return l.LoadJSON(strings.TrimSuffix(path.Base(file), catalogExtension), f)
+}
+
+// Message returns the best matching message of the code for the given
+// preferred locales, alongside the locale of the message.
+// acceptLanguage follows the format of the Accept-Language HTTP header.
+// e.g. "de-CH, de;q=0.9, en;q=0.8"
+// If none of the preferred locales have a message for the code, the message
+// of the default locale is returned.
+func (l *Localizer) Message(acceptLanguage string, code Code) (string, string, bool) {
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if key, ok := l.match(tag, code); ok {
+			return l.catalogs[key][code], l.locales[key], true
+		}
+	}
+
+	if msg, ok := l.catalogs[l.defaultLocale][code]; ok {
+		return msg, l.locales[l.defaultLocale], true
+	}
+
+	return "", "", false
+}
+
+// match finds the locale with a message for the code that matches the tag.
+// The tag itself is tried first, then its less specific prefixes,
+// and then any other locale with the same base language.
+func (l *Localizer) match(tag string, code Code) (string, bool) {
+	for candidate := tag; candidate != ""; {
+		if _, ok := l.catalogs[candidate][code]; ok {
+			return candidate, true
+		}
+
+		index := strings.LastIndex(candidate, "-")
+		if index < 0 {
+			break
+		}
+
+		candidate = candidate[:index]
+	}
+
+	base, _, _ := strings.Cut(tag, "-")
+	keys := make([]string, 0, len(l.catalogs))
+
+	for key := range l.catalogs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if keyBase, _, _ := strings.Cut(key, "-"); keyBase != base {
+			continue
+		}
+
+		if _, ok := l.catalogs[key][code]; ok {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+// LocalizedMessage returns the message of the error's code in the best matching
+// locale. acceptLanguage follows the format of the Accept-Language HTTP header.
+// e.g. "de-CH, de;q=0.9, en;q=0.8"
+// If the formatter has no localizer or no locale has a message for the code,
+// the default message of the error is returned.
+func (ge *GeneralError) LocalizedMessage(acceptLanguage string) string {
+	msg, _, ok := ge.localize(acceptLanguage)
+	if !ok {
+		return ge.DefaultMessage()
+	}
+
+	return msg
+}
+
+// ViewContext is the same as [GeneralError.View], but if the error's formatter has a localizer
+// and the context has the requester's preferred locales, the view also has the error message
+// in the best matching locale. Check [LocaleFromContext] and [WithLocalizer] for more information.
+func (ge *GeneralError) ViewContext(ctx context.Context) ErrorView {
+	view := ge.View()
+
+	acceptLanguage := LocaleFromContext(ctx)
+	if acceptLanguage == "" {
+		return view
+	}
+
+	if msg, locale, ok := ge.localize(acceptLanguage); ok {
+		view.LocalizedMessage, view.Locale = msg, locale
+	}
+
+	return view
+}
+
+func (ge *GeneralError) localize(acceptLanguage string) (string, string, bool) {
+	if ge.formatter.localizer == nil {
+		return "", "", false
+	}
+
+	text, locale, ok := ge.formatter.localizer.Message(acceptLanguage, ge.Code())
+	if !ok {
+		return "", "", false
+	}
+
+	ge.getDetails()
+
+	return ge.formatter.redactText(ge.formatter.renderMessage(text, ge.message, ge.labelStrings)), locale, true
+}
+
+// ContextWithLocale returns a copy of the context that holds the requester's
+// preferred locales in the format of the Accept-Language HTTP header.
+func ContextWithLocale(ctx context.Context, acceptLanguage string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, acceptLanguage)
+}
+
+// LocaleFromContext returns the requester's preferred locales stored by
+// [ContextWithLocale]. If there is none, it falls back to the "accept-language"
+// key of the incoming gRPC metadata.
+func LocaleFromContext(ctx context.Context) string {
+	if acceptLanguage, ok := ctx.Value(localeContextKey{}).(string); ok {
+		return acceptLanguage
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		return strings.Join(md.Get(acceptLanguageKey), ",")
+	}
+
+	return ""
+}
+
+// LocaleHandler is an HTTP middleware that stores the Accept-Language header
+// of the request in the request's context. Check [ContextWithLocale].
+func LocaleHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(ContextWithLocale(r.Context(), r.Header.Get(acceptLanguageKey))))
+	})
+}
+
+// parseAcceptLanguage returns the normalized language tags of an Accept-Language
+// value sorted by their quality, highest first. Tags with zero quality are dropped.
+func parseAcceptLanguage(acceptLanguage string) []string {
+	type weightedTag struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weightedTag
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = normalizeLocale(tag)
+
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err == nil {
+				quality = parsed
+			}
+		}
+
+		if quality <= 0 {
+			continue
+		}
+
+		tags = append(tags, weightedTag{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+
+	result := make([]string, len(tags))
+
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+
+	return result
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
@@ -0,0 +1,175 @@
+package gerrors_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/seinshah/gerrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newTestLocalizer(t *testing.T) *gerrors.Localizer {
+	t.Helper()
+
+	fsys := fstest.MapFS{
+		"locales/en.json":    {Data: []byte(`{"2": "{{.resource}} was not found"}`)},
+		"locales/de-DE.json": {Data: []byte(`{"2": "{{.resource}} wurde nicht gefunden"}`)},
+		"locales/fr.json":    {Data: []byte(`{"2": "{{.resource}} introuvable", "5": "erreur de stockage"}`)},
+	}
+
+	l := gerrors.NewLocalizer("en")
+	if err := l.LoadFS(fsys, "locales"); err != nil {
+		t.Fatalf("unexpected error loading locales: %v", err)
+	}
+
+	return l
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	t.Parallel()
+
+	f := gerrors.NewFormatter(gerrors.WithLocalizer(newTestLocalizer(t)))
+	err := f.New(errors.New("no rows"), gerrors.NotFound, "resource", "user")
+
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		expected       string
+	}{
+		{name: "exact match", acceptLanguage: "fr", expected: "user introuvable"},
+		{name: "case and separator insensitive", acceptLanguage: "DE_de", expected: "user wurde nicht gefunden"},
+		{name: "base language match", acceptLanguage: "de-CH", expected: "user wurde nicht gefunden"},
+		{name: "quality order", acceptLanguage: "it, fr;q=0.5, de;q=0.8", expected: "user wurde nicht gefunden"},
+		{name: "default locale", acceptLanguage: "it", expected: "user was not found"},
+		{name: "empty", acceptLanguage: "", expected: "user was not found"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if msg := err.LocalizedMessage(tc.acceptLanguage); msg != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, msg)
+			}
+		})
+	}
+
+	if msg := f.New(nil, gerrors.Internal).LocalizedMessage("fr"); msg != "there is an internal error in the system" {
+		t.Errorf("expected default message for codes without translation, got %q", msg)
+	}
+
+	if msg := gerrors.DefaultFormatter.New(nil, gerrors.NotFound).LocalizedMessage("fr"); msg == "" {
+		t.Errorf("expected default message without localizer")
+	}
+}
+
+func TestLocalizerInvalidCatalog(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{"en.json": {Data: []byte(`{"not-a-code": "message"}`)}}
+
+	if err := gerrors.NewLocalizer("en").LoadFS(fsys, "."); err == nil {
+		t.Errorf("expected error loading catalog with invalid code")
+	}
+}
+
+func TestGrpcErrorContext(t *testing.T) {
+	t.Parallel()
+
+	f := gerrors.NewFormatter(gerrors.WithLocalizer(newTestLocalizer(t)))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "fr-FR"))
+
+	st, ok := status.FromError(f.New(nil, gerrors.NotFound, "resource", "user").GrpcContext(ctx))
+	if !ok {
+		t.Fatal("expected gRPC status error")
+	}
+
+	if len(st.Details()) != 2 {
+		t.Fatalf("expected 2 details, got %d", len(st.Details()))
+	}
+
+	localized, ok := st.Details()[1].(*errdetails.LocalizedMessage)
+	if !ok {
+		t.Fatalf("expected LocalizedMessage detail, got %T", st.Details()[1])
+	}
+
+	if localized.GetLocale() != "fr" || localized.GetMessage() != "user introuvable" {
+		t.Errorf("unexpected localized message: %v", localized)
+	}
+}
+
+func TestLocaleHandler(t *testing.T) {
+	t.Parallel()
+
+	var locale string
+
+	handler := gerrors.LocaleHandler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		locale = gerrors.LocaleFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "de-DE, en;q=0.5")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if locale != "de-DE, en;q=0.5" {
+		t.Errorf("expected locale to be stored in context, got %q", locale)
+	}
+}
+
+func TestRenderWithContext(t *testing.T) {
+	t.Parallel()
+
+	f := gerrors.NewFormatter(gerrors.WithLocalizer(newTestLocalizer(t)))
+
+	handler := gerrors.LocaleHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		out, err := f.New(nil, gerrors.NotFound, "resource", "user").RenderWithContext(r.Context(), gerrors.JSONRenderer{})
+		if err != nil {
+			t.Errorf("unexpected error rendering: %v", err)
+		}
+
+		_, _ = w.Write([]byte(out))
+	}))
+
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		expected       string
+	}{
+		{
+			name:           "preferred locale",
+			acceptLanguage: "de-DE, en;q=0.5",
+			expected:       `"localized_message":"user wurde nicht gefunden","locale":"de-DE"`,
+		},
+		{
+			name:           "no preferred locale",
+			acceptLanguage: "",
+			expected:       "",
+		},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", tc.acceptLanguage)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		body := rec.Body.String()
+		if tc.expected == "" && strings.Contains(body, "localized_message") {
+			t.Errorf("%s: expected no localized message, got %s", tc.name, body)
+		}
+
+		if tc.expected != "" && !strings.Contains(body, tc.expected) {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, body)
+		}
+	}
+}
@@ -34,3 +34,18 @@ type debugLogger interface {
 type traceLogger interface {
 	Trace(msg string, keysAndValues ...any)
 }
+
+// warn logs a problem that the formatter worked around, at warn level if the logger supports it.
+func (f *Formatter) warn(err error, keysAndValues ...any) {
+	if f.logger == nil {
+		return
+	}
+
+	if l, ok := f.logger.(warnLogger); ok {
+		l.Warn(err.Error(), keysAndValues...)
+
+		return
+	}
+
+	f.logger.Error(err, err.Error(), keysAndValues...)
+}
@@ -0,0 +1,193 @@
+package gerrors
+
+import (
+	"bytes"
+	"container/list"
+	"sync"
+	"text/template"
+	"text/template/parse"
+)
+
+// messageTemplate is the parsed version of a [CoreMessageTemplate] template.
+type messageTemplate struct {
+	tpl    *template.Template
+	fields []string
+	err    error
+}
+
+// maxMessageTemplates is the number of parsed message templates that are cached.
+// Least recently used templates are evicted first, so that the templates of replaced
+// catalogs and localizers, e.g. by a [ReloadableLookuper], do not pile up.
+const maxMessageTemplates = 1024
+
+// messageTemplateCache caches the parsed message templates by their text,
+// since the same template is rendered for every error of a code.
+type messageTemplateCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order holds the cached templates, the most recently used first.
+	order *list.List
+	size  int
+}
+
+type messageTemplateEntry struct {
+	text string
+	mt   *messageTemplate
+}
+
+var messageTemplates = newMessageTemplateCache(maxMessageTemplates)
+
+func newMessageTemplateCache(size int) *messageTemplateCache {
+	return &messageTemplateCache{
+		mu:      sync.Mutex{},
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		size:    size,
+	}
+}
+
+func (c *messageTemplateCache) load(text string) (*messageTemplate, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[text]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	entry, _ := elem.Value.(*messageTemplateEntry)
+
+	return entry.mt, true
+}
+
+func (c *messageTemplateCache) store(text string, mt *messageTemplate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[text]; ok {
+		c.order.MoveToFront(elem)
+
+		return
+	}
+
+	c.entries[text] = c.order.PushFront(&messageTemplateEntry{text: text, mt: mt})
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		entry, _ := c.order.Remove(oldest).(*messageTemplateEntry)
+
+		delete(c.entries, entry.text)
+	}
+}
+
+// renderDefaultMessage returns the default message of the core error.
+// If the core error implements [CoreMessageTemplate], its template is filled in
+// from the labels. Check [Formatter.renderMessage] for more information.
+func (f *Formatter) renderDefaultMessage(core CoreError, labels map[string]string) string {
+	coret, ok := core.(CoreMessageTemplate)
+	if !ok || coret.GetMessageTemplate() == "" {
+		return core.GetDefaultMessage()
+	}
+
+	return f.renderMessage(coret.GetMessageTemplate(), core.GetDefaultMessage(), labels)
+}
+
+// renderMessage fills in the message template from the labels. Labels that are
+// referenced by the template but are missing are replaced with the missing value
+// replacement if it is allowed. Otherwise, or if the template fails, fallback
+// is returned.
+func (f *Formatter) renderMessage(text, fallback string, labels map[string]string) string {
+	mt := getMessageTemplate(text)
+	if mt.err != nil {
+		return fallback
+	}
+
+	data := make(map[string]string, len(labels)+len(mt.fields))
+
+	for k, v := range labels {
+		data[k] = v
+	}
+
+	for _, field := range mt.fields {
+		if _, ok := data[field]; ok {
+			continue
+		}
+
+		if !f.allowMissingValue {
+			return fallback
+		}
+
+		data[field] = f.missingValueReplacement
+	}
+
+	var buf bytes.Buffer
+
+	if err := mt.tpl.Execute(&buf, data); err != nil {
+		return fallback
+	}
+
+	return buf.String()
+}
+
+func getMessageTemplate(text string) *messageTemplate {
+	if mt, ok := messageTemplates.load(text); ok {
+		return mt
+	}
+
+	mt := &messageTemplate{}
+
+	mt.tpl, mt.err = template.New("message").Option("missingkey=zero").Parse(text)
+	if mt.err == nil {
+		mt.fields = templateFields(mt.tpl.Root, nil)
+	}
+
+	messageTemplates.store(text, mt)
+
+	return mt
+}
+
+// templateFields returns the name of the fields that are accessed on the
+// template's root data. Bodies of range and with actions are skipped since
+// the data changes in them.
+func templateFields(node parse.Node, fields []string) []string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return fields
+		}
+
+		for _, child := range n.Nodes {
+			fields = templateFields(child, fields)
+		}
+	case *parse.ActionNode:
+		fields = templateFields(n.Pipe, fields)
+	case *parse.IfNode:
+		fields = templateFields(n.Pipe, fields)
+		fields = templateFields(n.List, fields)
+		fields = templateFields(n.ElseList, fields)
+	case *parse.RangeNode:
+		fields = templateFields(n.Pipe, fields)
+		fields = templateFields(n.ElseList, fields)
+	case *parse.WithNode:
+		fields = templateFields(n.Pipe, fields)
+		fields = templateFields(n.ElseList, fields)
+	case *parse.PipeNode:
+		if n == nil {
+			return fields
+		}
+
+		for _, cmd := range n.Cmds {
+			fields = templateFields(cmd, fields)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			fields = templateFields(arg, fields)
+		}
+	case *parse.FieldNode:
+		fields = append(fields, n.Ident[0])
+	}
+
+	return fields
+}
@@ -0,0 +1,48 @@
+package gerrors
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestMessageTemplateCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	cache := newMessageTemplateCache(2)
+
+	for _, text := range []string{"a", "b"} {
+		cache.store(text, &messageTemplate{})
+	}
+
+	// "a" becomes the most recently used entry, so "b" is evicted first.
+	if _, ok := cache.load("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+
+	cache.store("c", &messageTemplate{})
+
+	for text, expected := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := cache.load(text); ok != expected {
+			t.Errorf("expected %s to be cached: %v, got %v", text, expected, ok)
+		}
+	}
+
+	if cache.order.Len() != 2 || len(cache.entries) != 2 {
+		t.Errorf("expected 2 cached templates, got %d", cache.order.Len())
+	}
+}
+
+func TestMessageTemplatesBounded(t *testing.T) {
+	t.Parallel()
+
+	for i := range maxMessageTemplates + 10 {
+		getMessageTemplate("attempt " + strconv.Itoa(i) + " of {{.limit}}")
+	}
+
+	messageTemplates.mu.Lock()
+	defer messageTemplates.mu.Unlock()
+
+	if messageTemplates.order.Len() > maxMessageTemplates || len(messageTemplates.entries) > maxMessageTemplates {
+		t.Errorf("expected at most %d cached templates, got %d", maxMessageTemplates, messageTemplates.order.Len())
+	}
+}
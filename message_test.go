@@ -0,0 +1,77 @@
+package gerrors_test
+
+import (
+	"testing"
+
+	"github.com/seinshah/gerrors"
+)
+
+type templatedCoreError struct {
+	gcoreErr
+}
+
+func TestMessageTemplate(t *testing.T) {
+	t.Parallel()
+
+	mapper := gerrors.NewMapper(gerrors.Code(100), map[gerrors.Code]gerrors.CoreError{
+		gerrors.Code(100): templatedCoreError{},
+	})
+
+	testCases := []struct {
+		name     string
+		options  []gerrors.FormatterOption
+		labels   []any
+		expected string
+	}{
+		{
+			name:     "all parameters",
+			labels:   []any{"user_id", 10, "region", "eu"},
+			expected: "user 10 not found in eu",
+		},
+		{
+			name:     "missing parameter",
+			labels:   []any{"user_id", 10},
+			expected: "user 10 not found in no-value",
+		},
+		{
+			name:     "missing parameter with custom replacement",
+			options:  []gerrors.FormatterOption{gerrors.WithMissingValueReplacement("?")},
+			labels:   []any{"user_id", 10},
+			expected: "user 10 not found in ?",
+		},
+		{
+			name:     "missing parameter with disabled replacement",
+			options:  []gerrors.FormatterOption{gerrors.WithDisabledMissingValueReplacement()},
+			labels:   []any{"user_id", 10},
+			expected: "custom core error",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := gerrors.NewFormatter(append(tc.options, gerrors.WithLookuper(mapper))...)
+			err := f.New(nil, gerrors.Code(100), tc.labels...)
+
+			if err.DefaultMessage() != tc.expected {
+				t.Errorf("expected default message %q, got %q", tc.expected, err.DefaultMessage())
+			}
+
+			if err.Metadata()[gerrors.MetadataDefaultMessage] != tc.expected {
+				t.Errorf("expected default message label %q, got %q",
+					tc.expected, err.Metadata()[gerrors.MetadataDefaultMessage])
+			}
+
+			if err.Error() != "error: custom(100) - "+tc.expected {
+				t.Errorf("expected rendered message in error, got %q", err.Error())
+			}
+		})
+	}
+}
+
+func (templatedCoreError) GetMessageTemplate() string {
+	return "user {{.user_id}} not found in {{.region}}"
+}
@@ -0,0 +1,335 @@
+package gerrors
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"slices"
+)
+
+// redactedError is the original error as it is passed to loggers, with a redacted message.
+// It does not unwrap to the original error, so that loggers walking the chain of errors
+// cannot reach the unredacted messages, but [errors.Is] still matches the original error
+// and the errors it wraps. [errors.As] does not, since it would expose them.
+type redactedError struct {
+	err     error
+	message string
+}
+
+// RedactionAction decides what happens to sensitive data found by a [RedactionRule].
+type RedactionAction int
+
+const (
+	// RedactMask replaces the sensitive data with "[REDACTED]".
+	RedactMask RedactionAction = iota
+
+	// RedactHash replaces the sensitive data with a salted hash of it, e.g. "[hash:9f86d081884c7d65]",
+	// so that occurrences of the same data can still be correlated. Check [WithRedactionSalt].
+	RedactHash
+
+	// RedactDrop drops the label, or removes the sensitive data from messages.
+	RedactDrop
+)
+
+const (
+	// minCardDigits and maxCardDigits are the number of digits of the card numbers found by [DetectCreditCards].
+	minCardDigits = 13
+	maxCardDigits = 19
+
+	// redactionMask replaces the sensitive data masked by [RedactMask].
+	redactionMask = "[REDACTED]"
+
+	// redactionHashLength is the number of hex characters of the hash kept by [RedactHash].
+	redactionHashLength = 16
+)
+
+// ValueDetector finds sensitive data in a label value or a message. It returns the
+// start and end indexes of every match, the same as [regexp.Regexp.FindAllStringIndex].
+type ValueDetector func(value string) [][]int
+
+// RedactionRule describes sensitive data and what to do with it. Check [WithRedaction].
+type RedactionRule struct {
+	// Keys matches the keys of labels whose whole value is sensitive. e.g. `(?i)password|token`
+	Keys *regexp.Regexp
+
+	// Values finds sensitive data in any label value and in messages. e.g. [DetectEmails]
+	Values ValueDetector
+
+	// Action decides what happens to the sensitive data.
+	Action RedactionAction
+}
+
+var (
+	// sensitiveKeyRE matches the label keys that are redacted by [DefaultRedactionRules].
+	sensitiveKeyRE = regexp.MustCompile(`(?i)passw(or)?d|secret|token|authorization|api[-_]?key|cookie|credential`)
+
+	emailRE       = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	digitRunRE    = regexp.MustCompile(`\b\d(?:[ -]?\d)*\b`)
+	jwtRE         = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	bearerTokenRE = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)
+	awsKeyRE      = regexp.MustCompile(
+		`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b|(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}`,
+	)
+)
+
+// WithRedaction adds redaction rules to the formatter. The rules are applied in order to
+// every label, including [MetadataOriginalError] and [MetadataDefaultMessage], and to
+// the rendered messages, before the error is logged or converted for any transport.
+// Labels whose value is redacted lose their type and become strings. Loggers receive the
+// original error with a redacted message, which matches it using [errors.Is] but does not
+// unwrap to it. Check [DefaultRedactionRules] for a sensible set of rules.
+//
+//	f := NewFormatter(
+//		WithRedaction(DefaultRedactionRules()...),
+//		WithRedaction(RedactionRule{Values: DetectPattern(`\bACME-\d{8}\b`), Action: RedactHash}),
+//		WithRedactionSalt(os.Getenv("REDACTION_SALT")),
+//	)
+func WithRedaction(rules ...RedactionRule) FormatterOption {
+	return func(f *Formatter) {
+		f.redactionRules = append(slices.Clone(f.redactionRules), rules...)
+	}
+}
+
+// WithRedactionSalt sets the salt of [RedactHash]. It should be kept secret and be
+// specific to the deployment, so that hashes cannot be reversed using precomputed tables.
+func WithRedactionSalt(salt string) FormatterOption {
+	return func(f *Formatter) {
+		f.redactionSalt = []byte(salt)
+	}
+}
+
+// DefaultRedactionRules returns rules that mask labels whose keys look like passwords, secrets,
+// tokens, authorization headers, API keys, cookies or credentials, and mask emails, credit card
+// numbers, JWTs, bearer tokens and AWS keys anywhere.
+func DefaultRedactionRules() []RedactionRule {
+	return []RedactionRule{
+		{Keys: sensitiveKeyRE, Values: nil, Action: RedactMask},
+		{Keys: nil, Values: DetectJWTs, Action: RedactMask},
+		{Keys: nil, Values: DetectBearerTokens, Action: RedactMask},
+		{Keys: nil, Values: DetectAWSKeys, Action: RedactMask},
+		{Keys: nil, Values: DetectEmails, Action: RedactMask},
+		{Keys: nil, Values: DetectCreditCards, Action: RedactMask},
+	}
+}
+
+// DetectPattern returns a [ValueDetector] that finds the matches of the regular expression.
+// It panics if the expression is invalid.
+func DetectPattern(expr string) ValueDetector {
+	re := regexp.MustCompile(expr)
+
+	return func(value string) [][]int {
+		return re.FindAllStringIndex(value, -1)
+	}
+}
+
+// DetectEmails is a [ValueDetector] that finds email addresses.
+func DetectEmails(value string) [][]int {
+	return emailRE.FindAllStringIndex(value, -1)
+}
+
+// DetectCreditCards is a [ValueDetector] that finds credit card numbers of 13 to 19 digits,
+// optionally separated by spaces or dashes, that pass the Luhn check. Card numbers are also
+// found next to other groups of digits, e.g. "order 12 4111 1111 1111 1111".
+func DetectCreditCards(value string) [][]int {
+	var result [][]int
+
+	for _, run := range digitRunRE.FindAllStringIndex(value, -1) {
+		groups := digitGroups(value, run[0], run[1])
+
+		for start := 0; start < len(groups); {
+			end := cardNumberEnd(value, groups[start:])
+			if end == 0 {
+				start++
+
+				continue
+			}
+
+			result = append(result, []int{groups[start][0], groups[start+end-1][1]})
+			start += end
+		}
+	}
+
+	return result
+}
+
+// digitGroups returns the start and end indexes of the groups of digits between start and end,
+// which are separated by spaces or dashes.
+func digitGroups(value string, start, end int) [][]int {
+	var groups [][]int
+
+	for i := start; i < end; i++ {
+		if value[i] < '0' || value[i] > '9' {
+			groups = append(groups, []int{start, i})
+			start = i + 1
+		}
+	}
+
+	return append(groups, []int{start, end})
+}
+
+// cardNumberEnd returns the number of leading groups of the longest card number that
+// passes the Luhn check, or 0 if there is none.
+func cardNumberEnd(value string, groups [][]int) int {
+	var (
+		digits int
+		end    int
+	)
+
+	for i, group := range groups {
+		digits += group[1] - group[0]
+		if digits > maxCardDigits {
+			break
+		}
+
+		if digits >= minCardDigits && luhnValid(value[groups[0][0]:group[1]]) {
+			end = i + 1
+		}
+	}
+
+	return end
+}
+
+// DetectJWTs is a [ValueDetector] that finds JSON Web Tokens.
+func DetectJWTs(value string) [][]int {
+	return jwtRE.FindAllStringIndex(value, -1)
+}
+
+// DetectBearerTokens is a [ValueDetector] that finds bearer tokens, e.g. "Bearer abc.def".
+func DetectBearerTokens(value string) [][]int {
+	return bearerTokenRE.FindAllStringIndex(value, -1)
+}
+
+// DetectAWSKeys is a [ValueDetector] that finds AWS access key IDs and assigned secret access keys.
+func DetectAWSKeys(value string) [][]int {
+	return awsKeyRE.FindAllStringIndex(value, -1)
+}
+
+// luhnValid reports whether the digits of the number pass the Luhn check.
+func luhnValid(number string) bool {
+	sum, digits := 0, 0
+
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		d := int(c - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		digits++
+	}
+
+	return digits >= minCardDigits && sum%10 == 0
+}
+
+// redactLabel applies the redaction rules to a label. It returns the redacted value,
+// false if the label should be dropped, and whether the value was changed.
+func (f *Formatter) redactLabel(key, value string) (string, bool, bool) {
+	changed := false
+
+	for _, rule := range f.redactionRules {
+		if rule.Keys != nil && rule.Keys.MatchString(key) {
+			if rule.Action == RedactDrop {
+				return "", false, true
+			}
+
+			return f.redactionReplacement(rule.Action, value), true, true
+		}
+
+		if rule.Values == nil {
+			continue
+		}
+
+		matches := rule.Values(value)
+		if len(matches) == 0 {
+			continue
+		}
+
+		if rule.Action == RedactDrop {
+			return "", false, true
+		}
+
+		value = f.redactMatches(rule.Action, value, matches)
+		changed = true
+	}
+
+	return value, true, changed
+}
+
+// redactText applies the value detectors of the redaction rules to a message.
+func (f *Formatter) redactText(text string) string {
+	for _, rule := range f.redactionRules {
+		if rule.Values == nil {
+			continue
+		}
+
+		if matches := rule.Values(text); len(matches) > 0 {
+			text = f.redactMatches(rule.Action, text, matches)
+		}
+	}
+
+	return text
+}
+
+// redactMatches replaces the matches, which are sorted and do not overlap, based on the action.
+func (f *Formatter) redactMatches(action RedactionAction, value string, matches [][]int) string {
+	result := make([]byte, 0, len(value))
+	last := 0
+
+	for _, match := range matches {
+		if match[0] < last {
+			continue
+		}
+
+		result = append(result, value[last:match[0]]...)
+		result = append(result, f.redactionReplacement(action, value[match[0]:match[1]])...)
+		last = match[1]
+	}
+
+	return string(append(result, value[last:]...))
+}
+
+// redactionReplacement returns the replacement of sensitive data based on the action.
+func (f *Formatter) redactionReplacement(action RedactionAction, value string) string {
+	switch action {
+	case RedactMask:
+		return redactionMask
+	case RedactHash:
+		mac := hmac.New(sha256.New, f.redactionSalt)
+		mac.Write([]byte(value))
+
+		return "[hash:" + hex.EncodeToString(mac.Sum(nil))[:redactionHashLength] + "]"
+	case RedactDrop:
+		return ""
+	default:
+		return redactionMask
+	}
+}
+
+// Error is part of error interface implementation.
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+// Is reports whether the original error matches the target. Check [errors.Is].
+func (e *redactedError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+// loggedError returns the original error as it is passed to loggers.
+func (ge *GeneralError) loggedError() error {
+	if len(ge.formatter.redactionRules) == 0 {
+		return ge.originalError
+	}
+
+	return &redactedError{err: ge.originalError, message: ge.formatter.redactText(ge.originalError.Error())}
+}
@@ -0,0 +1,260 @@
+package gerrors
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ErrCodeCollision is returned by [Registry.Register] whenever a [CoreError]
+// collides with an already registered one on its code, identifier or reason.
+var ErrCodeCollision = errors.New("error code collision")
+
+// errNilCore is returned by [Registry.Register] when it is called with a nil [CoreError].
+var errNilCore = errors.New("nil core error")
+
+// Registry is a concurrency-safe [Lookuper] whose codes can be registered at
+// runtime, e.g. from the init functions of the packages that own them.
+// Unlike [Mapper], codes can be added while formatters are using the registry.
+//
+//	var Errors = gerrors.NewRegistry(gerrors.Unknown)
+//
+//	func init() {
+//		Errors.MustRegister(invoiceNotFound)
+//	}
+type Registry struct {
+	mu           sync.RWMutex
+	unknownError Code
+	entries      map[Code]registration
+	codes        []Code
+	byIdentifier map[string]Code
+	byReason     map[string]Code
+}
+
+// registration is a registered [CoreError] and the package that registered it.
+type registration struct {
+	core CoreError
+	pkg  string
+}
+
+// NewRegistry creates an empty [Registry]. unknownErrorCode is used whenever
+// the registry cannot translate a [Code], once a [CoreError] is registered for it.
+func NewRegistry(unknownErrorCode Code) *Registry {
+	return &Registry{
+		mu:           sync.RWMutex{},
+		unknownError: unknownErrorCode,
+		entries:      make(map[Code]registration),
+		codes:        nil,
+		byIdentifier: make(map[string]Code),
+		byReason:     make(map[string]Code),
+	}
+}
+
+// Register adds the [CoreError] to the registry under its internal code.
+// It fails, wrapping [ErrCodeCollision], if the code, the identifier or the reason
+// of the [CoreError] is already registered. The error names the package that
+// registered the colliding [CoreError] first.
+func (r *Registry) Register(core CoreError) error {
+	return r.register(core, callerPackage())
+}
+
+// MustRegister is the same as [Registry.Register], but it panics on error.
+// It is meant to be called from init functions.
+func (r *Registry) MustRegister(core CoreError) {
+	if err := r.register(core, callerPackage()); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Registry) register(core CoreError, pkg string) error {
+	if core == nil {
+		return fmt.Errorf("%w: registered by %s", errNilCore, pkg)
+	}
+
+	code := core.GetInternalCode()
+	identifier := core.GetIdentifier()
+	reasons := reasonsOf(core)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+
+	if other, ok := r.entries[code]; ok {
+		errs = append(errs, fmt.Errorf("%w: code %d of %s is already registered by %s",
+			ErrCodeCollision, code, pkg, other.pkg))
+	}
+
+	if other, ok := r.byIdentifier[identifier]; ok {
+		errs = append(errs, fmt.Errorf("%w: identifier %q of %s is already registered by %s for code %d",
+			ErrCodeCollision, identifier, pkg, r.entries[other].pkg, other))
+	} else {
+		for _, reason := range reasons {
+			if other, ok := r.byReason[reason]; ok {
+				errs = append(errs, fmt.Errorf("%w: reason %q of %s is already registered by %s for code %d",
+					ErrCodeCollision, reason, pkg, r.entries[other].pkg, other))
+
+				break
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	r.entries[code] = registration{core: core, pkg: pkg}
+	r.byIdentifier[identifier] = code
+	for _, reason := range reasons {
+		r.byReason[reason] = code
+	}
+
+	index, _ := slices.BinarySearch(r.codes, code)
+	r.codes = slices.Insert(r.codes, index, code)
+
+	return nil
+}
+
+// Lookup helps [Registry] to implement [Lookuper] interface.
+// If the code is not registered, the unknown error code is used instead, and if that
+// is not registered either, a built-in last resort [CoreError] is returned.
+func (r *Registry) Lookup(code Code) CoreError {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if rec, ok := r.entries[code]; ok {
+		return rec.core
+	}
+
+	if rec, ok := r.entries[r.unknownError]; ok {
+		return rec.core
+	}
+
+	return lastResortCore(r.unknownError)
+}
+
+// TryLookup helps [Registry] to implement [TryLookuper] interface.
+func (r *Registry) TryLookup(code Code) (CoreError, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rec, ok := r.entries[code]
+
+	return rec.core, ok
+}
+
+// Codes helps [Registry] to implement [EnumerableLookuper] interface.
+// It returns all the registered codes, sorted.
+func (r *Registry) Codes() []Code {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.codes)
+}
+
+// All helps [Registry] to implement [EnumerableLookuper] interface.
+// It iterates over a snapshot of the registered codes, sorted by code,
+// so codes can be registered while iterating.
+func (r *Registry) All() iter.Seq2[Code, CoreError] {
+	return func(yield func(Code, CoreError) bool) {
+		r.mu.RLock()
+
+		snapshot := make([]CoreError, 0, len(r.codes))
+		for _, code := range r.codes {
+			snapshot = append(snapshot, r.entries[code].core)
+		}
+
+		r.mu.RUnlock()
+
+		for _, core := range snapshot {
+			if !yield(core.GetInternalCode(), core) {
+				return
+			}
+		}
+	}
+}
+
+// ByIdentifier helps [Registry] to implement [EnumerableLookuper] interface.
+func (r *Registry) ByIdentifier(identifier string) (CoreError, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	code, ok := r.byIdentifier[identifier]
+
+	return r.entries[code].core, ok
+}
+
+// ByReason helps [Registry] to implement [EnumerableLookuper] interface.
+func (r *Registry) ByReason(reason string) (CoreError, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	code, ok := r.byReason[reason]
+
+	return r.entries[code].core, ok
+}
+
+// ByGRPCCode helps [Registry] to implement [EnumerableLookuper] interface.
+// Only the codes whose [CoreError] implements [CoreGRPCError] are matched.
+func (r *Registry) ByGRPCCode(code codes.Code) []CoreError {
+	var result []CoreError
+
+	for _, core := range r.All() {
+		if coreg, ok := core.(CoreGRPCError); ok && coreg.GetGRPCCode() == code {
+			result = append(result, core)
+		}
+	}
+
+	return result
+}
+
+// Package returns the package that registered the code.
+func (r *Registry) Package(code Code) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rec, ok := r.entries[code]
+
+	return rec.pkg, ok
+}
+
+// gerrorsPackage is the import path of this package, whose frames are skipped by callerPackage.
+var gerrorsPackage = reflect.TypeOf(registration{}).PkgPath()
+
+// callerPackage returns the import path of the package that called into gerrors.
+func callerPackage() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+
+	for {
+		frame, more := frames.Next()
+
+		if pkg := funcPackage(frame.Function); pkg != "" && pkg != gerrorsPackage {
+			return pkg
+		}
+
+		if !more {
+			return "unknown package"
+		}
+	}
+}
+
+// funcPackage returns the import path of the package of a fully qualified function name,
+// e.g. "github.com/acme/billing" for "github.com/acme/billing.init.0".
+func funcPackage(function string) string {
+	lastSlash := strings.LastIndex(function, "/")
+
+	dot := strings.Index(function[lastSlash+1:], ".")
+	if dot < 0 {
+		return ""
+	}
+
+	return function[:lastSlash+1+dot]
+}
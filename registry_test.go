@@ -0,0 +1,159 @@
+package gerrors_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+)
+
+func TestRegistryRegister(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		core          gerrors.CoreError
+		expectedError string
+	}{
+		{
+			name:          "new code",
+			core:          composeCore{code: 1002, identifier: "payment-declined"},
+			expectedError: "",
+		},
+		{
+			name:          "code collision",
+			core:          composeCore{code: 1001, identifier: "invoice-missing"},
+			expectedError: "code 1001 of github.com/seinshah/gerrors_test is already registered by github.com/seinshah/gerrors_test",
+		},
+		{
+			name:          "identifier collision",
+			core:          composeCore{code: 1003, identifier: "invoice-not-found"},
+			expectedError: `identifier "invoice-not-found" of github.com/seinshah/gerrors_test is already registered`,
+		},
+		{
+			name:          "reason collision",
+			core:          composeCore{code: 1003, identifier: "Invoice-Not-Found"},
+			expectedError: `reason "INVOICE-NOT-FOUND" of github.com/seinshah/gerrors_test is already registered`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			registry := gerrors.NewRegistry(gerrors.Unknown)
+			registry.MustRegister(composeCore{code: 1001, identifier: "invoice-not-found"})
+
+			err := registry.Register(tc.core)
+
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, gerrors.ErrCodeCollision) || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("expected collision error containing %q, got %v", tc.expectedError, err)
+			}
+
+			if _, ok := registry.TryLookup(tc.core.GetInternalCode()); ok && tc.core.GetInternalCode() != 1001 {
+				t.Errorf("expected colliding code not to be registered")
+			}
+		})
+	}
+}
+
+func TestRegistryLookup(t *testing.T) {
+	t.Parallel()
+
+	registry := gerrors.NewRegistry(gerrors.Unknown)
+
+	if core := registry.Lookup(gerrors.NotFound); core == nil || core.GetIdentifier() != "unknown" {
+		t.Fatalf("expected last resort core error, got %v", core)
+	}
+
+	for _, core := range gerrors.GetDefaultMapping() {
+		registry.MustRegister(core)
+	}
+
+	if id := registry.Lookup(gerrors.NotFound).GetIdentifier(); id != "not-found" {
+		t.Errorf("expected not-found, got %s", id)
+	}
+
+	if code := registry.Lookup(1000).GetInternalCode(); code != gerrors.Unknown {
+		t.Errorf("expected unknown fallback, got %d", code)
+	}
+
+	if pkg, _ := registry.Package(gerrors.NotFound); pkg != "github.com/seinshah/gerrors_test" {
+		t.Errorf("expected registering package, got %s", pkg)
+	}
+
+	var enumerable gerrors.EnumerableLookuper = registry
+
+	if codes := enumerable.Codes(); len(codes) != len(gerrors.GetDefaultMapping()) || codes[0] != gerrors.Unknown {
+		t.Errorf("expected sorted codes, got %v", codes)
+	}
+
+	if core, ok := enumerable.ByReason("NOT-FOUND"); !ok || core.GetInternalCode() != gerrors.NotFound {
+		t.Errorf("expected lookup by reason, got %v", core)
+	}
+
+	if core, ok := enumerable.ByIdentifier("not-found"); !ok || core.GetInternalCode() != gerrors.NotFound {
+		t.Errorf("expected lookup by identifier, got %v", core)
+	}
+}
+
+func TestRegistryMustRegisterPanics(t *testing.T) {
+	t.Parallel()
+
+	registry := gerrors.NewRegistry(gerrors.Unknown)
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected MustRegister to panic on nil core error")
+		}
+	}()
+
+	registry.MustRegister(nil)
+}
+
+func TestRegistryConcurrency(t *testing.T) {
+	t.Parallel()
+
+	registry := gerrors.NewRegistry(gerrors.Unknown)
+	formatter := gerrors.NewFormatter(gerrors.WithLookuper(registry))
+
+	var wg sync.WaitGroup
+
+	for i := range 50 {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			registry.MustRegister(composeCore{code: gerrors.Code(1000 + i), identifier: "code-" + strconv.Itoa(i)})
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			_ = formatter.New(nil, gerrors.Code(1000+i))
+
+			for range registry.All() {
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if len(registry.Codes()) != 50 {
+		t.Errorf("expected 50 codes, got %d", len(registry.Codes()))
+	}
+}
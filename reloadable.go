@@ -0,0 +1,245 @@
+package gerrors
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+var errInvalidReloadInterval = errors.New("reload interval must be positive")
+
+// CatalogSource returns the current version of a JSON error catalog. Check [Catalog] for the format.
+// If the returned reader is also an [io.Closer], it is closed once the catalog is read.
+type CatalogSource func() (io.Reader, error)
+
+// FileCatalogSource returns a [CatalogSource] that reads the catalog from the file at path.
+func FileCatalogSource(path string) CatalogSource {
+	return func() (io.Reader, error) {
+		return os.Open(path)
+	}
+}
+
+// ReloadOption customizes a [ReloadableLookuper].
+type ReloadOption func(*ReloadableLookuper)
+
+// WithReloadInterval sets how often [ReloadableLookuper.Watch] polls the catalog source.
+// It defaults to 30 seconds and has to be positive.
+func WithReloadInterval(interval time.Duration) ReloadOption {
+	return func(r *ReloadableLookuper) {
+		r.interval = interval
+	}
+}
+
+// WithReloadErrorHandler sets the function that is called by [ReloadableLookuper.Watch]
+// whenever a new version of the catalog cannot be read or fails validation.
+// The last valid version of the catalog is kept in use.
+func WithReloadErrorHandler(handler func(error)) ReloadOption {
+	return func(r *ReloadableLookuper) {
+		r.errorHandler = handler
+	}
+}
+
+// ReloadableLookuper is an [EnumerableLookuper] over a catalog that can change
+// while the application is running, e.g. to fix messages, HTTP statuses or help URLs
+// without redeploying. New versions of the catalog are validated and swapped in
+// atomically, so formatters never observe a partially loaded catalog.
+//
+//	lookuper, err := gerrors.NewReloadableLookuper(gerrors.FileCatalogSource("errors.json"))
+//	if err != nil {
+//		return err
+//	}
+//
+//	go lookuper.Watch(ctx)
+//
+//	formatter := gerrors.NewFormatter(gerrors.WithLookuper(lookuper))
+type ReloadableLookuper struct {
+	source       CatalogSource
+	interval     time.Duration
+	errorHandler func(error)
+	current      atomic.Pointer[Mapper]
+
+	// reloadMu serializes the reloads, from reading the source to notifying the subscribers,
+	// so that an older version never replaces a newer one and subscribers are notified in order.
+	reloadMu sync.Mutex
+	last     []byte
+
+	// mu guards the subscribers.
+	mu          sync.Mutex
+	subscribers map[int]func(*Mapper)
+	nextID      int
+}
+
+// NewReloadableLookuper creates a [ReloadableLookuper] and loads the first version of the catalog,
+// which has to be valid.
+func NewReloadableLookuper(source CatalogSource, opts ...ReloadOption) (*ReloadableLookuper, error) {
+	r := &ReloadableLookuper{
+		source:       source,
+		interval:     30 * time.Second,
+		errorHandler: func(error) {},
+		current:      atomic.Pointer[Mapper]{},
+		reloadMu:     sync.Mutex{},
+		last:         nil,
+		mu:           sync.Mutex{},
+		subscribers:  make(map[int]func(*Mapper)),
+		nextID:       0,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.interval <= 0 {
+		return nil, fmt.Errorf("%w: %s", errInvalidReloadInterval, r.interval)
+	}
+
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload reads the catalog source and, if the catalog has changed and is valid, swaps it in
+// and notifies the subscribers. It reports whether a new version was swapped in.
+// On error, the last valid version is kept in use. Concurrent reloads run one at a time.
+func (r *ReloadableLookuper) Reload() (bool, error) {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	data, err := r.read()
+	if err != nil {
+		return false, err
+	}
+
+	if r.current.Load() != nil && bytes.Equal(data, r.last) {
+		return false, nil
+	}
+
+	mapper, err := LoadCatalog(bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+
+	r.last = data
+	r.current.Store(mapper)
+
+	// Subscribers are called without holding the lock of the subscribers,
+	// so that they can subscribe or unsubscribe.
+	r.mu.Lock()
+
+	subscribers := make([]func(*Mapper), 0, len(r.subscribers))
+	for _, subscriber := range r.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+
+	r.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(mapper)
+	}
+
+	return true, nil
+}
+
+// Watch polls the catalog source at the reload interval until the context is done.
+// Errors are passed to the handler set by [WithReloadErrorHandler].
+func (r *ReloadableLookuper) Watch(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Reload(); err != nil {
+				r.errorHandler(err)
+			}
+		}
+	}
+}
+
+// Subscribe registers a function that is called with the new [Mapper] whenever a new
+// version of the catalog is swapped in. Subscribers are called synchronously, in no
+// particular order, so they should not block. They may subscribe or unsubscribe while
+// being called, but they should not call [ReloadableLookuper.Reload], which waits for them.
+// The returned function unsubscribes.
+func (r *ReloadableLookuper) Subscribe(subscriber func(*Mapper)) func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextID
+	r.nextID++
+	r.subscribers[id] = subscriber
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		delete(r.subscribers, id)
+	}
+}
+
+// Mapper returns the [Mapper] of the catalog version that is currently in use.
+func (r *ReloadableLookuper) Mapper() *Mapper {
+	return r.current.Load()
+}
+
+// Lookup helps [ReloadableLookuper] to implement [Lookuper] interface.
+func (r *ReloadableLookuper) Lookup(code Code) CoreError {
+	return r.Mapper().Lookup(code)
+}
+
+// TryLookup helps [ReloadableLookuper] to implement [TryLookuper] interface.
+func (r *ReloadableLookuper) TryLookup(code Code) (CoreError, bool) {
+	return r.Mapper().TryLookup(code)
+}
+
+// Codes helps [ReloadableLookuper] to implement [EnumerableLookuper] interface.
+func (r *ReloadableLookuper) Codes() []Code {
+	return r.Mapper().Codes()
+}
+
+// All helps [ReloadableLookuper] to implement [EnumerableLookuper] interface.
+// It iterates over the catalog version that is in use when the iteration starts.
+func (r *ReloadableLookuper) All() iter.Seq2[Code, CoreError] {
+	return r.Mapper().All()
+}
+
+// ByIdentifier helps [ReloadableLookuper] to implement [EnumerableLookuper] interface.
+func (r *ReloadableLookuper) ByIdentifier(identifier string) (CoreError, bool) {
+	return r.Mapper().ByIdentifier(identifier)
+}
+
+// ByReason helps [ReloadableLookuper] to implement [EnumerableLookuper] interface.
+func (r *ReloadableLookuper) ByReason(reason string) (CoreError, bool) {
+	return r.Mapper().ByReason(reason)
+}
+
+// ByGRPCCode helps [ReloadableLookuper] to implement [EnumerableLookuper] interface.
+func (r *ReloadableLookuper) ByGRPCCode(code codes.Code) []CoreError {
+	return r.Mapper().ByGRPCCode(code)
+}
+
+// read reads the whole catalog from the source.
+func (r *ReloadableLookuper) read() ([]byte, error) {
+	reader, err := r.source()
+	if err != nil {
+		return nil, err
+	}
+
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	return io.ReadAll(reader)
+}
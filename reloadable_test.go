@@ -0,0 +1,257 @@
+package gerrors_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/seinshah/gerrors"
+)
+
+func TestReloadableLookuperReload(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		catalog = testCatalog
+	)
+
+	source := func() (io.Reader, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return strings.NewReader(catalog), nil
+	}
+
+	setCatalog := func(c string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		catalog = c
+	}
+
+	lookuper, err := gerrors.NewReloadableLookuper(source)
+	if err != nil {
+		t.Fatalf("unexpected error loading catalog: %v", err)
+	}
+
+	var notified []*gerrors.Mapper
+
+	unsubscribe := lookuper.Subscribe(func(m *gerrors.Mapper) {
+		notified = append(notified, m)
+	})
+
+	if reloaded, err := lookuper.Reload(); reloaded || err != nil {
+		t.Errorf("expected unchanged catalog not to be reloaded, got %v %v", reloaded, err)
+	}
+
+	setCatalog(strings.Replace(testCatalog, "user was not found", "user is missing", 1))
+
+	if reloaded, err := lookuper.Reload(); !reloaded || err != nil {
+		t.Fatalf("expected changed catalog to be reloaded, got %v %v", reloaded, err)
+	}
+
+	if msg := lookuper.Lookup(100).GetDefaultMessage(); msg != "user is missing" {
+		t.Errorf("expected new message, got %s", msg)
+	}
+
+	if len(notified) != 1 || notified[0] != lookuper.Mapper() {
+		t.Errorf("expected subscriber to be notified with the new mapper, got %v", notified)
+	}
+
+	setCatalog(`{"unknown_code": 9, "errors": []}`)
+
+	if reloaded, err := lookuper.Reload(); reloaded || !errors.Is(err, gerrors.ErrInvalidCatalog) {
+		t.Errorf("expected invalid catalog error, got %v %v", reloaded, err)
+	}
+
+	if msg := lookuper.Lookup(100).GetDefaultMessage(); msg != "user is missing" {
+		t.Errorf("expected last valid catalog to be kept, got %s", msg)
+	}
+
+	unsubscribe()
+	setCatalog(testCatalog)
+
+	if reloaded, _ := lookuper.Reload(); !reloaded || len(notified) != 1 {
+		t.Errorf("expected unsubscribed function not to be notified, got %d notifications", len(notified))
+	}
+}
+
+func TestReloadableLookuperInvalidSource(t *testing.T) {
+	t.Parallel()
+
+	_, err := gerrors.NewReloadableLookuper(gerrors.FileCatalogSource(filepath.Join(t.TempDir(), "missing.json")))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected missing file error, got %v", err)
+	}
+}
+
+func TestReloadableLookuperInvalidInterval(t *testing.T) {
+	t.Parallel()
+
+	source := func() (io.Reader, error) { return strings.NewReader(testCatalog), nil }
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if _, err := gerrors.NewReloadableLookuper(source, gerrors.WithReloadInterval(interval)); err == nil {
+			t.Errorf("expected error for reload interval %s", interval)
+		}
+	}
+}
+
+func TestReloadableLookuperSubscriberUnsubscribes(t *testing.T) {
+	t.Parallel()
+
+	catalog := testCatalog
+	source := func() (io.Reader, error) { return strings.NewReader(catalog), nil }
+
+	lookuper, err := gerrors.NewReloadableLookuper(source)
+	if err != nil {
+		t.Fatalf("unexpected error loading catalog: %v", err)
+	}
+
+	var (
+		notified    int
+		unsubscribe func()
+	)
+
+	unsubscribe = lookuper.Subscribe(func(*gerrors.Mapper) {
+		notified++
+
+		unsubscribe()
+	})
+
+	catalog = strings.Replace(testCatalog, "user was not found", "user is missing", 1)
+
+	if reloaded, err := lookuper.Reload(); !reloaded || err != nil {
+		t.Fatalf("expected changed catalog to be reloaded, got %v %v", reloaded, err)
+	}
+
+	catalog = testCatalog
+
+	if reloaded, _ := lookuper.Reload(); !reloaded || notified != 1 {
+		t.Errorf("expected the subscriber to be notified once, got %d notifications", notified)
+	}
+}
+
+func TestReloadableLookuperConcurrentReloads(t *testing.T) {
+	t.Parallel()
+
+	var version atomic.Int64
+
+	// Every read returns a newer version of the catalog.
+	source := func() (io.Reader, error) {
+		message := fmt.Sprintf("user was not found v%04d", version.Add(1))
+
+		// Reads take different times, so that overlapping reloads finish out of order.
+		time.Sleep(time.Duration(rand.IntN(1000)) * time.Microsecond)
+
+		return strings.NewReader(strings.Replace(testCatalog, "user was not found", message, 1)), nil
+	}
+
+	lookuper, err := gerrors.NewReloadableLookuper(source)
+	if err != nil {
+		t.Fatalf("unexpected error loading catalog: %v", err)
+	}
+
+	var notified []string
+
+	lookuper.Subscribe(func(m *gerrors.Mapper) {
+		notified = append(notified, m.Lookup(100).GetDefaultMessage())
+	})
+
+	var wg sync.WaitGroup
+
+	for range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := lookuper.Reload(); err != nil {
+				t.Errorf("unexpected error reloading: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if !slices.IsSorted(notified) || len(notified) != 20 {
+		t.Errorf("expected the subscriber to be notified of every version in order, got %v", notified)
+	}
+
+	if current := lookuper.Lookup(100).GetDefaultMessage(); current != notified[len(notified)-1] {
+		t.Errorf("expected the last notified version %q to be in use, got %q", notified[len(notified)-1], current)
+	}
+}
+
+func TestReloadableLookuperWatch(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "errors.json")
+	if err := os.WriteFile(path, []byte(testCatalog), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 10)
+
+	lookuper, err := gerrors.NewReloadableLookuper(
+		gerrors.FileCatalogSource(path),
+		gerrors.WithReloadInterval(5*time.Millisecond),
+		gerrors.WithReloadErrorHandler(func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error loading catalog: %v", err)
+	}
+
+	reloaded := make(chan *gerrors.Mapper, 1)
+	lookuper.Subscribe(func(m *gerrors.Mapper) {
+		reloaded <- m
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go lookuper.Watch(ctx)
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, gerrors.ErrInvalidCatalog) {
+			t.Errorf("expected invalid catalog error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the error handler to be called")
+	}
+
+	newCatalog := strings.Replace(testCatalog, "quota exceeded", "slow down", 1)
+	if err := os.WriteFile(path, []byte(newCatalog), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case m := <-reloaded:
+		if msg := m.Lookup(101).GetDefaultMessage(); msg != "slow down" {
+			t.Errorf("expected new message, got %s", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the catalog to be reloaded")
+	}
+}
@@ -0,0 +1,153 @@
+package gerrors
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Renderer converts the information of an error to its string representation.
+// [GeneralError.Error] uses the formatter's renderer which can be customized
+// using [WithRenderer]. Any renderer can be used for a single call as well,
+// using [GeneralError.RenderWith].
+// Available implementations are [TemplateRenderer], [JSONRenderer] and [LogfmtRenderer].
+type Renderer interface {
+	Render(view ErrorView) (string, error)
+}
+
+// ErrorView holds the information of a [GeneralError] that is passed to renderers.
+// Its fields are the variables that are available in templates.
+// Check [WithTemplate] for more information.
+type ErrorView struct {
+	Identifier     string            `json:"identifier"`
+	Domain         string            `json:"domain,omitempty"`
+	ErrorCode      string            `json:"error_code"`
+	GrpcErrorCode  string            `json:"grpc_error_code,omitempty"`
+	Message        string            `json:"message"`
+	DefaultMessage string            `json:"default_message"`
+	Operation      string            `json:"operation,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Stack          string            `json:"stack,omitempty"`
+
+	// LocalizedMessage and Locale are the message in the requester's preferred locale and its locale.
+	// They are only set by [GeneralError.ViewContext]. Check [WithLocalizer].
+	LocalizedMessage string `json:"localized_message,omitempty"`
+	Locale           string `json:"locale,omitempty"`
+
+	// systemKeys are the system keys of the labels, as they are named by the formatter.
+	systemKeys []string
+}
+
+// JSONRenderer renders errors as a single-line JSON object.
+// System labels are left out of labels since they are already part of the object.
+//
+//	{"identifier":"not-found","error_code":"2","grpc_error_code":"5","message":"...","labels":{"user":"john"}}
+type JSONRenderer struct{}
+
+// LogfmtRenderer renders errors as logfmt key values, followed by labels sorted by key.
+// System labels are left out since they are already part of the output.
+//
+//	identifier=not-found error_code=2 grpc_error_code=5 message="..." user=john
+type LogfmtRenderer struct{}
+
+// WithRenderer customizes how errors of the formatter are rendered by [GeneralError.Error].
+// By default, the formatter template is used. Check [WithTemplate] for more information.
+func WithRenderer(r Renderer) FormatterOption {
+	return func(f *Formatter) {
+		f.renderer = r
+	}
+}
+
+// RenderWith renders the error using the given renderer instead of the formatter's renderer.
+func (ge *GeneralError) RenderWith(r Renderer) (string, error) {
+	return r.Render(ge.View())
+}
+
+// RenderWithContext is the same as [GeneralError.RenderWith], but the error is rendered with the
+// message in the requester's preferred locale, e.g. for HTTP responses of requests that went
+// through [LocaleHandler]. Check [GeneralError.ViewContext].
+func (ge *GeneralError) RenderWithContext(ctx context.Context, r Renderer) (string, error) {
+	return r.Render(ge.ViewContext(ctx))
+}
+
+// Render is part of [Renderer] interface implementation.
+func (JSONRenderer) Render(view ErrorView) (string, error) {
+	view.Labels = userLabels(view.Labels, view.systemKeys)
+
+	b, err := json.Marshal(view)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// Render is part of [Renderer] interface implementation.
+func (LogfmtRenderer) Render(view ErrorView) (string, error) {
+	var sb strings.Builder
+
+	writeLogfmt(&sb, "identifier", view.Identifier, true)
+	writeLogfmt(&sb, "domain", view.Domain, false)
+	writeLogfmt(&sb, "error_code", view.ErrorCode, true)
+	writeLogfmt(&sb, "grpc_error_code", view.GrpcErrorCode, false)
+	writeLogfmt(&sb, "message", view.Message, true)
+	writeLogfmt(&sb, "default_message", view.DefaultMessage, true)
+	writeLogfmt(&sb, "operation", view.Operation, false)
+	writeLogfmt(&sb, "localized_message", view.LocalizedMessage, false)
+	writeLogfmt(&sb, "locale", view.Locale, false)
+
+	for _, label := range sortedLabelPairs(userLabels(view.Labels, view.systemKeys)) {
+		writeLogfmt(&sb, label.Key, label.Value, true)
+	}
+
+	writeLogfmt(&sb, "stack", view.Stack, false)
+
+	return sb.String(), nil
+}
+
+func writeLogfmt(sb *strings.Builder, key, value string, always bool) {
+	if value == "" && !always {
+		return
+	}
+
+	if sb.Len() > 0 {
+		sb.WriteByte(' ')
+	}
+
+	sb.WriteString(key)
+	sb.WriteByte('=')
+
+	if value == "" || strings.ContainsAny(value, " =\"\\\t\n\r") {
+		value = strconv.Quote(value)
+	}
+
+	sb.WriteString(value)
+}
+
+// userLabels returns the labels without the system labels.
+func userLabels(labels map[string]string, keys []string) map[string]string {
+	filtered := make(map[string]string, len(labels))
+
+	for k, v := range labels {
+		if isSystemKey(k, keys) {
+			continue
+		}
+
+		filtered[k] = v
+	}
+
+	return filtered
+}
+
+// isSystemKey reports whether the key is one of the system keys. If no system keys
+// are given, e.g. for views that are not created by [GeneralError.View], the default
+// system keys are used.
+func isSystemKey(key string, keys []string) bool {
+	if keys == nil {
+		keys = systemKeys
+	}
+
+	return slices.Contains(keys, key)
+}
@@ -0,0 +1,88 @@
+package gerrors_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+)
+
+func TestRenderers(t *testing.T) {
+	t.Parallel()
+
+	tplRenderer, err := gerrors.NewTemplateRenderer(`{{.Identifier}}: {{label "user" "-"}}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating template renderer: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		renderer gerrors.Renderer
+		expected string
+	}{
+		{
+			name:     "template",
+			renderer: tplRenderer,
+			expected: "not-found: john doe",
+		},
+		{
+			name:     "json",
+			renderer: gerrors.JSONRenderer{},
+			expected: `{"identifier":"not-found","error_code":"2","grpc_error_code":"5","message":"no rows",` +
+				`"default_message":"no record was found with given information","operation":"repo",` +
+				`"labels":{"id":"1","user":"john doe"}}`,
+		},
+		{
+			name:     "logfmt",
+			renderer: gerrors.LogfmtRenderer{},
+			expected: `identifier=not-found error_code=2 grpc_error_code=5 message="no rows" ` +
+				`default_message="no record was found with given information" operation=repo id=1 user="john doe"`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := gerrors.NewFormatter(gerrors.WithRenderer(tc.renderer))
+
+			wrapped := gerrors.Wrap(f.New(errors.New("no rows"), gerrors.NotFound, "user", "john doe"), "repo", "id", 1)
+
+			var ge *gerrors.GeneralError
+			if !errors.As(wrapped, &ge) {
+				t.Fatal("expected GeneralError")
+			}
+
+			if ge.Error() != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, ge.Error())
+			}
+
+			out, rerr := gerrors.DefaultFormatter.New(nil, gerrors.Internal).RenderWith(tc.renderer)
+			if rerr != nil || out == "" {
+				t.Errorf("expected per-call rendering to succeed, got %q (%v)", out, rerr)
+			}
+		})
+	}
+}
+
+func TestJSONRendererIsParsable(t *testing.T) {
+	t.Parallel()
+
+	out, err := gerrors.DefaultFormatter.New(errors.New(`quote " and newline
+`), gerrors.Internal, "key", "value").RenderWith(gerrors.JSONRenderer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var view gerrors.ErrorView
+	if err := json.Unmarshal([]byte(out), &view); err != nil {
+		t.Fatalf("expected valid JSON, got %s: %v", out, err)
+	}
+
+	if view.Identifier != "internal" || view.Labels["key"] != "value" {
+		t.Errorf("unexpected parsed view: %+v", view)
+	}
+}
@@ -0,0 +1,483 @@
+package gerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	openAPIVersion    = "3.1.0"
+	jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	errorInfoType     = "type.googleapis.com/google.rpc.ErrorInfo"
+
+	// errorSchemaName and errorInfoSchemaName are the names of the shared schemas.
+	errorSchemaName     = "Error"
+	errorInfoSchemaName = "ErrorInfo"
+)
+
+// errSchemaNameCollision is returned when two identifiers have the same schema name.
+var errSchemaNameCollision = errors.New("identifiers have the same schema name")
+
+// SchemaOption customizes the documents generated by [WriteOpenAPI] and [WriteJSONSchema].
+type SchemaOption func(*schemaConfig)
+
+type schemaConfig struct {
+	title     string
+	version   string
+	formatter *Formatter
+}
+
+// schemaCode is the information of a single code that is used in the schemas.
+type schemaCode struct {
+	name       string
+	code       Code
+	identifier string
+	reason     string
+	message    string
+	status     string
+	httpStatus int
+	labels     []LabelSpec
+	example    map[string]any
+}
+
+// WithSchemaTitle sets the title of the generated document. It defaults to "Errors".
+func WithSchemaTitle(title string) SchemaOption {
+	return func(c *schemaConfig) {
+		c.title = title
+	}
+}
+
+// WithSchemaVersion sets the version of the generated OpenAPI document. It defaults to "1.0.0".
+func WithSchemaVersion(version string) SchemaOption {
+	return func(c *schemaConfig) {
+		c.version = version
+	}
+}
+
+// WithSchemaFormatter sets the formatter that is used to build the example payloads.
+// By default, a formatter with the documented lookuper is used.
+func WithSchemaFormatter(f *Formatter) SchemaOption {
+	return func(c *schemaConfig) {
+		c.formatter = f
+	}
+}
+
+// WriteOpenAPI writes an OpenAPI 3.1 document with the error schemas and responses of
+// all the codes of the lookuper. Error bodies follow the AIP-193 JSON representation:
+//
+//	{
+//	  "error": {
+//	    "code": 404,
+//	    "message": "...",
+//	    "status": "NOT_FOUND",
+//	    "details": [{"@type": "type.googleapis.com/google.rpc.ErrorInfo", "reason": "...", "metadata": {...}}]
+//	  }
+//	}
+//
+// The shared "Error" and "ErrorInfo" schemas list every reason, identifier and known
+// label key as enum values and properties. Each code has its own schema, e.g.
+// "UserNotFoundError", that pins its HTTP status, gRPC status and reason and describes
+// its labels, typed after its label schema if any (see [CoreLabelSchema]), and its own
+// response object, e.g. "UserNotFound", that can be referenced by the API operations:
+//
+//	responses:
+//	  "404":
+//	    $ref: "errors.json#/components/responses/UserNotFound"
+func WriteOpenAPI(w io.Writer, l EnumerableLookuper, opts ...SchemaOption) error {
+	config := newSchemaConfig(opts)
+
+	schemaCodes, err := collectSchemaCodes(l, config.formatter)
+	if err != nil {
+		return err
+	}
+
+	const refPrefix = "#/components/schemas/"
+
+	schemas := map[string]any{
+		errorSchemaName:     errorSchema(schemaCodes, refPrefix),
+		errorInfoSchemaName: errorInfoSchema(schemaCodes, config.formatter),
+	}
+	responses := make(map[string]any, len(schemaCodes))
+
+	for _, sc := range schemaCodes {
+		schemas[sc.name+"Error"] = codeSchema(sc, refPrefix, config.formatter)
+		responses[sc.name] = map[string]any{
+			"description": sc.message,
+			"content": map[string]any{
+				"application/json": map[string]any{
+					"schema":  map[string]any{"$ref": refPrefix + sc.name + "Error"},
+					"example": sc.example,
+				},
+			},
+		}
+	}
+
+	return writeJSON(w, map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":   config.title,
+			"version": config.version,
+		},
+		"components": map[string]any{
+			"schemas":   schemas,
+			"responses": responses,
+		},
+	})
+}
+
+// WriteJSONSchema writes a standalone JSON Schema (draft 2020-12) of the error payload
+// of all the codes of the lookuper. It is the same as the "Error" schema of [WriteOpenAPI].
+func WriteJSONSchema(w io.Writer, l EnumerableLookuper, opts ...SchemaOption) error {
+	config := newSchemaConfig(opts)
+
+	schemaCodes, err := collectSchemaCodes(l, config.formatter)
+	if err != nil {
+		return err
+	}
+
+	schema := errorSchema(schemaCodes, "#/$defs/")
+	schema["$schema"] = jsonSchemaDialect
+	schema["title"] = config.title
+	schema["$defs"] = map[string]any{
+		errorInfoSchemaName: errorInfoSchema(schemaCodes, config.formatter),
+	}
+
+	return writeJSON(w, schema)
+}
+
+func newSchemaConfig(opts []SchemaOption) *schemaConfig {
+	config := &schemaConfig{
+		title:     "Errors",
+		version:   "1.0.0",
+		formatter: nil,
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if config.formatter == nil {
+		config.formatter = NewFormatter()
+	}
+
+	return config
+}
+
+func collectSchemaCodes(l EnumerableLookuper, f *Formatter) ([]schemaCode, error) {
+	result := make([]schemaCode, 0)
+	names := make(map[string]string)
+
+	for _, core := range l.All() {
+		sc := newSchemaCode(f.Clone(), l, core)
+
+		if other, ok := names[sc.name]; ok {
+			return nil, fmt.Errorf("%w: %q and %q are both named %s",
+				errSchemaNameCollision, other, sc.identifier, sc.name)
+		}
+
+		names[sc.name] = sc.identifier
+		result = append(result, sc)
+	}
+
+	return result, nil
+}
+
+func newSchemaCode(f *Formatter, l Lookuper, core CoreError) schemaCode {
+	f.coreDataLookup = l
+
+	grpcCode := codes.Unknown
+	if coreg, ok := core.(CoreGRPCError); ok {
+		grpcCode = coreg.GetGRPCCode()
+	}
+
+	sc := schemaCode{
+		name:       schemaName(core.GetIdentifier()),
+		code:       core.GetInternalCode(),
+		identifier: core.GetIdentifier(),
+		reason:     f.reasonOf(core),
+		message:    core.GetDefaultMessage(),
+		status:     canonicalGRPCCode(grpcCode),
+		httpStatus: httpStatusFromGRPC(grpcCode),
+		labels:     labelSpecsOf(core),
+		example:    nil,
+	}
+
+	if coreh, ok := core.(CoreHTTPError); ok {
+		sc.httpStatus = coreh.GetHTTPStatus()
+	}
+
+	// The example error is created without logging it.
+	ge := f.createError(nil, sc.code)
+	ge.addLabels(exampleLabels(sc.labels))
+	info := ge.getDetails()
+
+	sc.example = map[string]any{
+		"error": map[string]any{
+			"code":    sc.httpStatus,
+			"message": ge.Error(),
+			"status":  sc.status,
+			"details": []any{
+				map[string]any{
+					"@type":    errorInfoType,
+					"reason":   info.GetReason(),
+					"metadata": info.GetMetadata(),
+				},
+			},
+		},
+	}
+
+	return sc
+}
+
+// errorSchema returns the schema of the AIP-193 error body shared by all the codes.
+func errorSchema(schemaCodes []schemaCode, refPrefix string) map[string]any {
+	var (
+		httpStatuses []int
+		statuses     []string
+	)
+
+	for _, sc := range schemaCodes {
+		if !slices.Contains(httpStatuses, sc.httpStatus) {
+			httpStatuses = append(httpStatuses, sc.httpStatus)
+		}
+
+		if !slices.Contains(statuses, sc.status) {
+			statuses = append(statuses, sc.status)
+		}
+	}
+
+	sort.Ints(httpStatuses)
+	sort.Strings(statuses)
+
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"error"},
+		"properties": map[string]any{
+			"error": map[string]any{
+				"type":     "object",
+				"required": []string{"code", "message", "status"},
+				"properties": map[string]any{
+					"code":    map[string]any{"type": "integer", "description": "HTTP status code.", "enum": httpStatuses},
+					"message": map[string]any{"type": "string", "description": "Developer-facing error message."},
+					"status":  map[string]any{"type": "string", "description": "gRPC status code.", "enum": statuses},
+					"details": map[string]any{
+						"type":     "array",
+						"items":    map[string]any{"type": "object", "required": []string{"@type"}},
+						"contains": map[string]any{"$ref": refPrefix + errorInfoSchemaName},
+					},
+				},
+			},
+		},
+	}
+}
+
+// errorInfoSchema returns the schema of the ErrorInfo detail shared by all the codes.
+// System keys are named as they are sent by the formatter.
+func errorInfoSchema(schemaCodes []schemaCode, f *Formatter) map[string]any {
+	var (
+		reasons     []string
+		identifiers []string
+		errorCodes  []string
+		labelKeys   []string
+	)
+
+	for _, sc := range schemaCodes {
+		reasons = append(reasons, sc.reason)
+		identifiers = append(identifiers, sc.identifier)
+		errorCodes = append(errorCodes, strconv.Itoa(int(sc.code)))
+
+		for _, spec := range sc.labels {
+			if !slices.Contains(labelKeys, spec.Key) {
+				labelKeys = append(labelKeys, spec.Key)
+			}
+		}
+	}
+
+	sort.Strings(labelKeys)
+
+	metadata := make(map[string]any, len(systemKeys)+len(labelKeys))
+
+	for _, key := range labelKeys {
+		metadata[f.keyCase.convert(key)] = map[string]any{"type": "string"}
+	}
+
+	for _, key := range systemKeys {
+		property := map[string]any{"type": "string"}
+
+		switch key {
+		case MetadataIdentifier:
+			property["enum"] = identifiers
+		case MetadataErrorCode:
+			property["enum"] = errorCodes
+		}
+
+		f.setSchemaKey(metadata, key, property)
+	}
+
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"@type", "reason", "metadata"},
+		"properties": map[string]any{
+			"@type":  map[string]any{"const": errorInfoType},
+			"reason": map[string]any{"type": "string", "enum": reasons},
+			"domain": map[string]any{"type": "string"},
+			"metadata": map[string]any{
+				"type":                 "object",
+				"required":             f.schemaKeys(MetadataIdentifier, MetadataErrorCode, MetadataDefaultMessage),
+				"properties":           metadata,
+				"additionalProperties": map[string]any{"type": "string"},
+			},
+		},
+	}
+}
+
+// codeSchema returns the schema of the error body of a single code.
+func codeSchema(sc schemaCode, refPrefix string, f *Formatter) map[string]any {
+	metadataRequired := f.schemaKeys(MetadataIdentifier, MetadataErrorCode)
+	metadataProperties := make(map[string]any, 2+len(sc.labels))
+
+	for _, spec := range sc.labels {
+		key := f.keyCase.convert(spec.Key)
+		metadataProperties[key] = labelSchema(spec)
+
+		if spec.Required {
+			metadataRequired = append(metadataRequired, key)
+		}
+	}
+
+	f.setSchemaKey(metadataProperties, MetadataIdentifier, map[string]any{"const": sc.identifier})
+	f.setSchemaKey(metadataProperties, MetadataErrorCode, map[string]any{"const": strconv.Itoa(int(sc.code))})
+
+	return map[string]any{
+		"description": sc.message,
+		"allOf": []any{
+			map[string]any{"$ref": refPrefix + errorSchemaName},
+			map[string]any{
+				"properties": map[string]any{
+					"error": map[string]any{
+						"properties": map[string]any{
+							"code":   map[string]any{"const": sc.httpStatus},
+							"status": map[string]any{"const": sc.status},
+							"details": map[string]any{
+								"contains": map[string]any{
+									"properties": map[string]any{
+										"@type":  map[string]any{"const": errorInfoType},
+										"reason": map[string]any{"const": sc.reason},
+										"metadata": map[string]any{
+											"required":   metadataRequired,
+											"properties": metadataProperties,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// labelSchema returns the schema of a label's string value, based on its declared type
+// and the default value encoders.
+func labelSchema(spec LabelSpec) map[string]any {
+	schema := map[string]any{"type": "string"}
+
+	// nolint: exhaustive
+	switch spec.Type {
+	case LabelTypeInteger:
+		schema["pattern"] = `^-?[0-9]+$`
+	case LabelTypeNumber:
+		schema["pattern"] = `^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`
+	case LabelTypeBoolean:
+		schema["enum"] = []string{"true", "false"}
+	case LabelTypeTime:
+		schema["format"] = "date-time"
+	}
+
+	if spec.Description != "" {
+		schema["description"] = spec.Description
+	}
+
+	return schema
+}
+
+// schemaKeys returns the names of the enabled system keys, as they are sent by the formatter.
+func (f *Formatter) schemaKeys(keys ...string) []string {
+	names := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		if name, ok := f.systemKey(key); ok {
+			names = append(names, f.keyCase.convert(name))
+		}
+	}
+
+	return names
+}
+
+// setSchemaKey sets the schema of the system key under its name, unless it is disabled.
+func (f *Formatter) setSchemaKey(properties map[string]any, key string, schema any) {
+	if name, ok := f.systemKey(key); ok {
+		properties[f.keyCase.convert(name)] = schema
+	}
+}
+
+// schemaName converts an identifier to a schema name. e.g. "user-not-found" to "UserNotFound".
+// Check [IdentifierWords].
+func schemaName(identifier string) string {
+	var sb strings.Builder
+
+	for _, word := range IdentifierWords(identifier) {
+		runes := []rune(word)
+		sb.WriteString(strings.ToUpper(string(runes[0])) + string(runes[1:]))
+	}
+
+	return sb.String()
+}
+
+// canonicalGRPCCodes are the canonical names of the gRPC codes, as defined by google.rpc.Code.
+var canonicalGRPCCodes = map[codes.Code]string{
+	codes.OK:                 "OK",
+	codes.Canceled:           "CANCELLED",
+	codes.Unknown:            "UNKNOWN",
+	codes.InvalidArgument:    "INVALID_ARGUMENT",
+	codes.DeadlineExceeded:   "DEADLINE_EXCEEDED",
+	codes.NotFound:           "NOT_FOUND",
+	codes.AlreadyExists:      "ALREADY_EXISTS",
+	codes.PermissionDenied:   "PERMISSION_DENIED",
+	codes.ResourceExhausted:  "RESOURCE_EXHAUSTED",
+	codes.FailedPrecondition: "FAILED_PRECONDITION",
+	codes.Aborted:            "ABORTED",
+	codes.OutOfRange:         "OUT_OF_RANGE",
+	codes.Unimplemented:      "UNIMPLEMENTED",
+	codes.Internal:           "INTERNAL",
+	codes.Unavailable:        "UNAVAILABLE",
+	codes.DataLoss:           "DATA_LOSS",
+	codes.Unauthenticated:    "UNAUTHENTICATED",
+}
+
+// canonicalGRPCCode returns the canonical name of the gRPC code. e.g. NOT_FOUND.
+// Unknown codes are named UNKNOWN.
+func canonicalGRPCCode(code codes.Code) string {
+	if name, ok := canonicalGRPCCodes[code]; ok {
+		return name
+	}
+
+	return canonicalGRPCCodes[codes.Unknown]
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(v)
+}
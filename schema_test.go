@@ -0,0 +1,172 @@
+package gerrors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+)
+
+func TestWriteOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := gerrors.ParseCatalog(strings.NewReader(testCatalog))
+	if err != nil {
+		t.Fatalf("unexpected error parsing catalog: %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := gerrors.WriteOpenAPI(&buf, catalog.Mapper(), gerrors.WithSchemaVersion("2.0.0")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		Components struct {
+			Schemas   map[string]json.RawMessage `json:"schemas"`
+			Responses map[string]json.RawMessage `json:"responses"`
+		} `json:"components"`
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("expected valid JSON document: %v", err)
+	}
+
+	if doc.OpenAPI != "3.1.0" || doc.Info.Version != "2.0.0" {
+		t.Errorf("unexpected document header: %s %s", doc.OpenAPI, doc.Info.Version)
+	}
+
+	for _, name := range []string{"Error", "ErrorInfo", "UnknownError", "UserNotFoundError", "QuotaError"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("expected schema %s", name)
+		}
+	}
+
+	for _, name := range []string{"Unknown", "UserNotFound", "Quota"} {
+		if _, ok := doc.Components.Responses[name]; !ok {
+			t.Errorf("expected response %s", name)
+		}
+	}
+
+	for _, expected := range []string{
+		`"const": "NOT_FOUND"`,
+		`"const": "USER-NOT-FOUND"`,
+		`"const": 429`,
+		`"user_id": {`,
+		`"$ref": "#/components/schemas/UserNotFoundError"`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected document to contain %s", expected)
+		}
+	}
+}
+
+func TestWriteJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := gerrors.ParseCatalog(strings.NewReader(testCatalog))
+	if err != nil {
+		t.Fatalf("unexpected error parsing catalog: %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := gerrors.WriteJSONSchema(&buf, catalog.Mapper(), gerrors.WithSchemaTitle("API error")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var schema struct {
+		Schema string                     `json:"$schema"`
+		Title  string                     `json:"title"`
+		Defs   map[string]json.RawMessage `json:"$defs"`
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &schema); err != nil {
+		t.Fatalf("expected valid JSON schema: %v", err)
+	}
+
+	if schema.Schema != "https://json-schema.org/draft/2020-12/schema" || schema.Title != "API error" {
+		t.Errorf("unexpected schema header: %s %s", schema.Schema, schema.Title)
+	}
+
+	info := string(schema.Defs["ErrorInfo"])
+
+	for _, expected := range []string{`"UNKNOWN"`, `"USER-NOT-FOUND"`, `"QUOTA"`, `"user-not-found"`, `"user_id"`} {
+		if !strings.Contains(info, expected) {
+			t.Errorf("expected ErrorInfo schema to contain %s:\n%s", expected, info)
+		}
+	}
+
+	if !strings.Contains(buf.String(), `"$ref": "#/$defs/ErrorInfo"`) {
+		t.Errorf("expected details to reference ErrorInfo definition")
+	}
+}
+
+func TestWriteOpenAPINameCollision(t *testing.T) {
+	t.Parallel()
+
+	catalog := &gerrors.Catalog{
+		UnknownCode: 1,
+		Entries: []*gerrors.CatalogEntry{
+			{Code: 1, Identifier: "not-found"},
+			{Code: 2, Identifier: "not_found"},
+		},
+	}
+
+	if err := catalog.Validate(); err != nil {
+		t.Fatalf("unexpected error validating catalog: %v", err)
+	}
+
+	if err := gerrors.WriteOpenAPI(&bytes.Buffer{}, catalog.Mapper()); err == nil {
+		t.Errorf("expected error for colliding schema names")
+	}
+}
+
+func TestWriteOpenAPICanonicalStatus(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		grpcCode string
+		expected string
+	}{
+		{grpcCode: "Canceled", expected: "CANCELLED"},
+		{grpcCode: "InvalidArgument", expected: "INVALID_ARGUMENT"},
+		{grpcCode: "Unauthenticated", expected: "UNAUTHENTICATED"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.grpcCode, func(t *testing.T) {
+			t.Parallel()
+
+			catalog := &gerrors.Catalog{
+				UnknownCode: 1,
+				Entries: []*gerrors.CatalogEntry{
+					{Code: 1, Identifier: "unknown"},
+					{Code: 2, Identifier: "failure", GRPCCode: tc.grpcCode},
+				},
+			}
+
+			if err := catalog.Validate(); err != nil {
+				t.Fatalf("unexpected error validating catalog: %v", err)
+			}
+
+			var buf bytes.Buffer
+
+			if err := gerrors.WriteOpenAPI(&buf, catalog.Mapper()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !strings.Contains(buf.String(), `"const": "`+tc.expected+`"`) {
+				t.Errorf("expected status %s, got:\n%s", tc.expected, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package gerrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+const (
+	// mainTemplateName is the name of the template that is executed by
+	// [GeneralError.Error].
+	mainTemplateName = "gerror"
+
+	// maxStackDepth is the maximum number of frames recorded for an error
+	// when stack traces are enabled.
+	maxStackDepth = 32
+)
+
+// LabelPair is a single label of an error. It is the element type of the
+// "sortedLabels" template function.
+type LabelPair struct {
+	Key   string
+	Value string
+}
+
+// WithTemplateFuncs registers custom functions that can be used in the
+// formatter's templates. Custom functions override the built-in functions
+// with the same name. Built-in functions are:
+//
+//   - label "key" "default": the value of the label or the default if it is missing.
+//
+//   - hasLabel "key": whether the error has the label.
+//
+//   - sortedLabels: the error's labels as a list of [LabelPair] sorted by key.
+//
+//   - upper, lower: converts the string to upper or lower case.
+//
+//   - json: encodes the value as JSON.
+//
+//   - truncate n: cuts the string to n characters. e.g. {{.Message | truncate 20}}
+//
+//   - join "sep": joins a list of strings. e.g. {{.List | join ", "}}
+//
+//   - stack: the stack trace of the error if [WithStackTrace] is used, otherwise empty.
+func WithTemplateFuncs(funcs template.FuncMap) FormatterOption {
+	return func(f *Formatter) {
+		for name, fn := range funcs {
+			f.templateFuncs[name] = fn
+		}
+	}
+}
+
+// WithNamedTemplate adds a named sub-template to the formatter next to the main
+// template that is set using [WithTemplate]. Named templates follow the same syntax
+// and variables as the main template and can be rendered by [GeneralError.Render].
+// Templates defined in the main template using {{define "name"}} can be rendered
+// the same way.
+//
+//	f := NewFormatter(
+//		WithNamedTemplate("short", "{{.Identifier}}"),
+//		WithNamedTemplate("long", "{{.Identifier}}({{.ErrorCode}}) - {{.Message}}"),
+//	)
+func WithNamedTemplate(name, templateString string) FormatterOption {
+	return func(f *Formatter) {
+		f.namedTemplates[name] = templateString
+	}
+}
+
+// WithStackTrace records the stack trace of every error created by the formatter.
+// The stack trace is available in templates through the "stack" function.
+func WithStackTrace() FormatterOption {
+	return func(f *Formatter) {
+		f.stackTrace = true
+	}
+}
+
+// TemplateRenderer is the [Renderer] that renders errors using text/template.
+// It is the default renderer of every formatter, built from the templates set by
+// [WithTemplate] and [WithNamedTemplate].
+type TemplateRenderer struct {
+	template *template.Template
+	funcs    template.FuncMap
+	bound    sync.Pool
+}
+
+// boundTemplate is a clone of the renderer's templates whose view functions, e.g. "label",
+// read the view that is being rendered. Clones are built once and reused through a pool,
+// so that rendering does not clone the templates every time.
+type boundTemplate struct {
+	template *template.Template
+	view     *ErrorView
+}
+
+// NewTemplateRenderer creates a [TemplateRenderer] from a text/template string.
+// The template can use the variables explained in [WithTemplate] and the built-in
+// functions explained in [WithTemplateFuncs], alongside the provided funcs.
+// It returns an error if the template is invalid.
+func NewTemplateRenderer(templateString string, funcs template.FuncMap) (*TemplateRenderer, error) {
+	return newTemplateRenderer(templateString, nil, funcs)
+}
+
+func newTemplateRenderer(
+	templateString string,
+	namedTemplates map[string]string,
+	funcs template.FuncMap,
+) (*TemplateRenderer, error) {
+	tpl := template.New(mainTemplateName).Funcs(builtinTemplateFuncs()).Funcs(funcs)
+
+	if _, err := tpl.Parse(templateString); err != nil {
+		return nil, err
+	}
+
+	for name, text := range namedTemplates {
+		if _, err := tpl.New(name).Parse(text); err != nil {
+			return nil, err
+		}
+	}
+
+	return &TemplateRenderer{
+		template: tpl,
+		funcs:    funcs,
+		bound:    sync.Pool{},
+	}, nil
+}
+
+// Render is part of [Renderer] interface implementation.
+// It renders the main template.
+func (r *TemplateRenderer) Render(view ErrorView) (string, error) {
+	return r.RenderTemplate(mainTemplateName, view)
+}
+
+// RenderTemplate renders the named sub-template. Check [WithNamedTemplate] for more information.
+func (r *TemplateRenderer) RenderTemplate(name string, view ErrorView) (string, error) {
+	if r.template.Lookup(name) == nil {
+		return "", fmt.Errorf("%w: %s", errUnknownTemplate, name)
+	}
+
+	bt, ok := r.bound.Get().(*boundTemplate)
+	if !ok {
+		tpl, err := r.template.Clone()
+		if err != nil {
+			return "", err
+		}
+
+		bt = &boundTemplate{template: tpl, view: nil}
+		tpl.Funcs(bt.viewFuncs(r.funcs))
+	}
+
+	bt.view = &view
+
+	defer func() {
+		bt.view = nil
+		r.bound.Put(bt)
+	}()
+
+	var buf bytes.Buffer
+
+	if err := bt.template.ExecuteTemplate(&buf, name, view); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// Render renders the error using the formatter's named template.
+// Check [WithNamedTemplate] for more information.
+func (ge *GeneralError) Render(name string) (string, error) {
+	return ge.formatter.templateRenderer.RenderTemplate(name, ge.View())
+}
+
+// viewFuncs returns the template functions that depend on the data of the error being rendered.
+// Custom functions with the same name override them.
+func (bt *boundTemplate) viewFuncs(custom template.FuncMap) template.FuncMap {
+	funcs := template.FuncMap{
+		"label": func(key, defaultValue string) string {
+			if v, ok := bt.view.Labels[key]; ok {
+				return v
+			}
+
+			return defaultValue
+		},
+		"hasLabel": func(key string) bool {
+			_, ok := bt.view.Labels[key]
+
+			return ok
+		},
+		"sortedLabels": func() []LabelPair {
+			return sortedLabelPairs(bt.view.Labels)
+		},
+		"stack": func() string {
+			return bt.view.Stack
+		},
+	}
+
+	for name := range funcs {
+		if fn, ok := custom[name]; ok {
+			funcs[name] = fn
+		}
+	}
+
+	return funcs
+}
+
+func (ge *GeneralError) stackTrace() string {
+	if len(ge.stack) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	frames := runtime.CallersFrames(ge.stack)
+
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+
+		if !more {
+			break
+		}
+	}
+
+	return sb.String()
+}
+
+// captureStack returns the program counters of the caller of the function
+// that creates the error.
+func captureStack() []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(4, pcs)
+
+	return pcs[:n]
+}
+
+func builtinTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"label":        func(_, defaultValue string) string { return defaultValue },
+		"hasLabel":     func(string) bool { return false },
+		"sortedLabels": func() []LabelPair { return nil },
+		"stack":        func() string { return "" },
+		"upper":        strings.ToUpper,
+		"lower":        strings.ToLower,
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+
+			return string(b), err
+		},
+		"truncate": func(n int, s string) string {
+			r := []rune(s)
+			if n < 0 || len(r) <= n {
+				return s
+			}
+
+			return string(r[:n])
+		},
+		"join": func(sep string, elems []string) string {
+			return strings.Join(elems, sep)
+		},
+	}
+}
+
+func sortedLabelPairs(labels map[string]string) []LabelPair {
+	pairs := make([]LabelPair, 0, len(labels))
+
+	for k, v := range labels {
+		pairs = append(pairs, LabelPair{Key: k, Value: v})
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Key < pairs[j].Key
+	})
+
+	return pairs
+}
@@ -0,0 +1,162 @@
+package gerrors_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+
+	"github.com/seinshah/gerrors"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "label with existing key",
+			template: `{{label "user" "anonymous"}}`,
+			expected: "john",
+		},
+		{
+			name:     "label with missing key",
+			template: `{{label "region" "global"}}`,
+			expected: "global",
+		},
+		{
+			name:     "has label",
+			template: `{{if hasLabel "user"}}yes{{end}}{{if hasLabel "region"}}no{{end}}`,
+			expected: "yes",
+		},
+		{
+			name:     "upper and lower",
+			template: `{{upper .Identifier}} {{lower "ABC"}}`,
+			expected: "NOT-FOUND abc",
+		},
+		{
+			name:     "json",
+			template: `{{json .Message}}`,
+			expected: `"record \"1\" is missing"`,
+		},
+		{
+			name:     "truncate",
+			template: `{{.Message | truncate 6}}`,
+			expected: "record",
+		},
+		{
+			name:     "join",
+			template: `{{split "a,b" | join "-"}}`,
+			expected: "a-b",
+		},
+		{
+			name:     "sorted labels",
+			template: `{{range sortedLabels}}{{if not (hasPrefix .Key "_")}}{{.Key}}={{.Value}};{{end}}{{end}}`,
+			expected: "age=30;user=john;",
+		},
+		{
+			name:     "stack without trace",
+			template: `[{{stack}}]`,
+			expected: "[]",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := gerrors.NewFormatter(
+				gerrors.WithTemplate(tc.template),
+				gerrors.WithTemplateFuncs(template.FuncMap{
+					"split":     func(s string) []string { return strings.Split(s, ",") },
+					"hasPrefix": strings.HasPrefix,
+				}),
+			)
+
+			err := f.New(errors.New(`record "1" is missing`), gerrors.NotFound, "user", "john", "age", 30)
+
+			if err.Error() != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestTemplateFuncsConcurrent(t *testing.T) {
+	t.Parallel()
+
+	f := gerrors.NewFormatter(gerrors.WithTemplate(`{{label "user" ""}}`))
+
+	var wg sync.WaitGroup
+
+	for i := range 50 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			user := strconv.Itoa(i)
+
+			if got := f.New(nil, gerrors.NotFound, "user", user).Error(); got != user {
+				t.Errorf("expected %q, got %q", user, got)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestNamedTemplates(t *testing.T) {
+	t.Parallel()
+
+	f := gerrors.NewFormatter(
+		gerrors.WithTemplate(`{{define "inline"}}inline: {{.Identifier}}{{end}}{{template "long" .}}`),
+		gerrors.WithNamedTemplate("short", "{{.Identifier}}"),
+		gerrors.WithNamedTemplate("long", "{{.Identifier}}({{.ErrorCode}}) - {{.Message}}"),
+	)
+
+	err := f.New(nil, gerrors.Storage)
+
+	expected := map[string]string{
+		"short":  "storage",
+		"long":   "storage(5) - unable to perform storage-related operation",
+		"inline": "inline: storage",
+	}
+
+	for name, out := range expected {
+		rendered, rerr := err.Render(name)
+		if rerr != nil {
+			t.Errorf("unexpected error rendering %s: %v", name, rerr)
+		}
+
+		if rendered != out {
+			t.Errorf("expected %s template to be %q, got %q", name, out, rendered)
+		}
+	}
+
+	if err.Error() != expected["long"] {
+		t.Errorf("expected main template to be %q, got %q", expected["long"], err.Error())
+	}
+
+	if _, rerr := err.Render("missing"); rerr == nil {
+		t.Errorf("expected error rendering an undefined template")
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	t.Parallel()
+
+	f := gerrors.NewFormatter(gerrors.WithStackTrace(), gerrors.WithTemplate("{{stack}}"))
+	err := gerrors.Wrap(f.New(nil, gerrors.Internal), "op")
+
+	if !strings.Contains(err.Error(), "gerrors_test.TestStackTrace") {
+		t.Errorf("expected stack trace to start from the caller, got %s", err.Error())
+	}
+}
@@ -0,0 +1,320 @@
+package gerrors
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// tsCode is the information of a single code that is used in the TypeScript module.
+type tsCode struct {
+	Name       string
+	Code       Code
+	Identifier string
+	Reason     string
+	Message    string
+	Labels     []tsLabel
+}
+
+// tsLabel is a single label of a code in the TypeScript module.
+type tsLabel struct {
+	Key      string
+	Required bool
+	Doc      string
+}
+
+// tsModule is the data passed to the TypeScript template.
+type tsModule struct {
+	Codes []tsCode
+	// Reasons has the code of every reason. Like [Mapper.ByReason], the lowest code wins
+	// whenever two codes share a reason.
+	Reasons    []tsCode
+	SystemKeys []string
+}
+
+// WriteTypeScript writes a TypeScript module for the clients of the codes of the lookuper.
+// The module contains:
+//   - an ErrorCode enum, and ErrorIdentifier and ErrorReason unions,
+//   - a labels interface per code whose message template or label schema has label keys,
+//     e.g. UserNotFoundLabels, where labels that are not required are optional,
+//   - type guards for AIP-193 JSON bodies (see [WriteOpenAPI]) and problem+json bodies
+//     that carry the reason and metadata of the error as extension members,
+//   - parseError, which extracts a typed error from either body.
+//
+// Clients can then narrow errors by their reason:
+//
+//	const err = parseError(await response.json());
+//	if (err && hasReason(err, "USER-NOT-FOUND")) {
+//	  console.log(err.labels.user_id);
+//	}
+//
+// Reasons and label keys are the ones sent by the formatter set by [WithSchemaFormatter],
+// so a formatter in strict AIP-193 mode generates UPPER_SNAKE_CASE reasons and lowerCamelCase
+// label keys. Check [WithAIP193]. Other [SchemaOption]s are ignored.
+// Every reason is listed once. When codes share a reason, it is mapped to the lowest one,
+// which is the code that [Mapper.ByReason] finds.
+func WriteTypeScript(w io.Writer, l EnumerableLookuper, opts ...SchemaOption) error {
+	config := newSchemaConfig(opts)
+
+	schemaCodes, err := collectSchemaCodes(l, config.formatter)
+	if err != nil {
+		return err
+	}
+
+	module := tsModule{
+		Codes:      make([]tsCode, 0, len(schemaCodes)),
+		Reasons:    make([]tsCode, 0, len(schemaCodes)),
+		SystemKeys: make([]string, 0, len(systemKeys)*3),
+	}
+
+	// System keys are recognized as they are named by the formatter, and by their default
+	// names in both cases, since servers in strict AIP-193 mode send them in lowerCamelCase.
+	for _, key := range append(slices.Clone(systemKeys), config.formatter.outputSystemKeys()...) {
+		for _, name := range []string{key, KeyCaseLowerCamel.convert(key)} {
+			if !slices.Contains(module.SystemKeys, name) {
+				module.SystemKeys = append(module.SystemKeys, name)
+			}
+		}
+	}
+
+	for _, sc := range schemaCodes {
+		name := sc.name
+		if name == "" || unicode.IsDigit([]rune(name)[0]) {
+			name = "Code" + name
+		}
+
+		code := tsCode{
+			Name:       name,
+			Code:       sc.code,
+			Identifier: sc.identifier,
+			Reason:     sc.reason,
+			Message:    sc.message,
+			Labels:     tsLabels(sc.labels, config.formatter.keyCase),
+		}
+
+		module.Codes = append(module.Codes, code)
+
+		if !slices.ContainsFunc(module.Reasons, func(c tsCode) bool { return c.Reason == code.Reason }) {
+			module.Reasons = append(module.Reasons, code)
+		}
+	}
+
+	return typeScriptTemplate.Execute(w, module)
+}
+
+// tsLabels returns the labels of a code in the TypeScript module. Label values are
+// always sent as strings, so their declared type is only documented.
+func tsLabels(specs []LabelSpec, keyCase KeyCase) []tsLabel {
+	labels := make([]tsLabel, 0, len(specs))
+
+	for _, spec := range specs {
+		doc := spec.Description
+		if spec.Type != LabelTypeAny && spec.Type != LabelTypeString {
+			doc = strings.TrimSpace(doc + " Type: " + string(spec.Type) + ".")
+		}
+
+		labels = append(labels, tsLabel{Key: keyCase.convert(spec.Key), Required: spec.Required, Doc: doc})
+	}
+
+	return labels
+}
+
+var typeScriptTemplate = template.Must(template.New("typescript").Funcs(template.FuncMap{
+	"quote": func(s string) (string, error) {
+		b, err := json.Marshal(s)
+
+		return string(b), err
+	},
+	"comment": func(s string) string {
+		return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "*/", "*\\/")
+	},
+}).Parse(`// Code generated by gerrors. DO NOT EDIT.
+
+/** Internal codes of the errors. */
+export enum ErrorCode {
+{{- range .Codes}}
+  /** {{comment .Message}} */
+  {{.Name}} = {{.Code}},
+{{- end}}
+}
+
+/** Identifiers of the errors, as sent in the "_identifier" metadata. */
+export type ErrorIdentifier =
+{{- range .Codes}}
+  | {{quote .Identifier}}
+{{- end}};
+
+/** Reasons of the errors, as sent in the ErrorInfo detail. */
+export type ErrorReason =
+{{- range .Reasons}}
+  | {{quote .Reason}}
+{{- end}};
+{{range .Codes}}{{if .Labels}}
+/** Labels of {{quote .Reason}} errors. */
+export interface {{.Name}}Labels {
+{{- range .Labels}}
+{{- if .Doc}}
+  /** {{comment .Doc}} */
+{{- end}}
+  {{quote .Key}}{{if not .Required}}?{{end}}: string;
+{{- end}}
+}
+{{end}}{{end}}
+/** Labels of the errors by their reason. */
+export interface ErrorLabelsByReason {
+{{- range .Reasons}}
+  {{quote .Reason}}: {{if .Labels}}{{.Name}}Labels{{else}}Record<string, string>{{end}};
+{{- end}}
+}
+
+/** Codes of the errors by their reason. */
+export const errorCodeByReason: Record<ErrorReason, ErrorCode> = {
+{{- range .Reasons}}
+  {{quote .Reason}}: ErrorCode.{{.Name}},
+{{- end}}
+};
+
+/** Identifiers of the errors by their reason. */
+export const errorIdentifierByReason: Record<ErrorReason, ErrorIdentifier> = {
+{{- range .Reasons}}
+  {{quote .Reason}}: {{quote .Identifier}},
+{{- end}}
+};
+
+/** Metadata keys that are set by gerrors and are not labels. */
+const systemKeys: ReadonlySet<string> = new Set([
+{{- range $i, $key := .SystemKeys}}{{if $i}}, {{end}}{{quote $key}}{{end -}}
+]);
+
+const errorInfoType = "type.googleapis.com/google.rpc.ErrorInfo";
+
+/** ErrorInfo detail of an error. */
+export interface ErrorInfo {
+  "@type": typeof errorInfoType;
+  reason: string;
+  domain?: string;
+  metadata?: Record<string, string>;
+}
+
+/** AIP-193 JSON representation of an error. */
+export interface AIP193Error {
+  error: {
+    code: number;
+    message: string;
+    status: string;
+    details?: Array<{ "@type": string; [key: string]: unknown }>;
+  };
+}
+
+/** RFC 9457 problem details, with the reason and metadata of the error as extension members. */
+export interface ProblemDetails {
+  type?: string;
+  title?: string;
+  status?: number;
+  detail?: string;
+  instance?: string;
+  reason?: string;
+  metadata?: Record<string, string>;
+}
+
+/** A typed error parsed from a response body. */
+export interface GerrorsError<R extends ErrorReason = ErrorReason> {
+  code: ErrorCode;
+  identifier: ErrorIdentifier;
+  reason: R;
+  message: string;
+  labels: ErrorLabelsByReason[R];
+  metadata: Record<string, string>;
+}
+
+function isObject(value: unknown): value is Record<string, unknown> {
+  return typeof value === "object" && value !== null && !Array.isArray(value);
+}
+
+/** Reports whether the value is a known error reason. */
+export function isErrorReason(value: unknown): value is ErrorReason {
+  return typeof value === "string" && Object.prototype.hasOwnProperty.call(errorCodeByReason, value);
+}
+
+/** Reports whether the value is an ErrorInfo detail. */
+export function isErrorInfo(value: unknown): value is ErrorInfo {
+  return isObject(value) && value["@type"] === errorInfoType && typeof value["reason"] === "string";
+}
+
+/** Reports whether the body is an AIP-193 JSON error. */
+export function isAIP193Error(body: unknown): body is AIP193Error {
+  if (!isObject(body)) {
+    return false;
+  }
+
+  const error = body["error"];
+
+  return (
+    isObject(error) &&
+    typeof error["code"] === "number" &&
+    typeof error["message"] === "string" &&
+    typeof error["status"] === "string" &&
+    (error["details"] === undefined || Array.isArray(error["details"]))
+  );
+}
+
+/** Reports whether the body is a problem+json error. */
+export function isProblemDetails(body: unknown): body is ProblemDetails {
+  return (
+    isObject(body) &&
+    (body["type"] === undefined || typeof body["type"] === "string") &&
+    (body["title"] === undefined || typeof body["title"] === "string") &&
+    (body["status"] === undefined || typeof body["status"] === "number") &&
+    (body["detail"] === undefined || typeof body["detail"] === "string")
+  );
+}
+
+/** Narrows the error to the given reason, which types its labels. */
+export function hasReason<R extends ErrorReason>(err: GerrorsError, reason: R): err is GerrorsError<R> {
+  return err.reason === reason;
+}
+
+/**
+ * Extracts a typed error from an AIP-193 JSON or a problem+json body.
+ * It returns undefined if the body is neither, or if its reason is unknown.
+ */
+export function parseError(body: unknown): GerrorsError | undefined {
+  let reason: unknown;
+  let message = "";
+  let metadata: Record<string, string> = {};
+
+  if (isAIP193Error(body)) {
+    const info = (body.error.details ?? []).find(isErrorInfo);
+    reason = info?.reason;
+    message = body.error.message;
+    metadata = info?.metadata ?? {};
+  } else if (isProblemDetails(body)) {
+    reason = body.reason;
+    message = body.detail ?? body.title ?? "";
+    metadata = isObject(body.metadata) ? body.metadata : {};
+  }
+
+  if (!isErrorReason(reason)) {
+    return undefined;
+  }
+
+  const labels: Record<string, string> = {};
+  for (const [key, value] of Object.entries(metadata)) {
+    if (!systemKeys.has(key)) {
+      labels[key] = value;
+    }
+  }
+
+  return {
+    code: errorCodeByReason[reason],
+    identifier: errorIdentifierByReason[reason],
+    reason,
+    message,
+    labels: labels as unknown as ErrorLabelsByReason[ErrorReason],
+    metadata,
+  };
+}
+`))
@@ -0,0 +1,151 @@
+package gerrors_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+)
+
+func TestWriteTypeScript(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := gerrors.ParseCatalog(strings.NewReader(testCatalog))
+	if err != nil {
+		t.Fatalf("unexpected error parsing catalog: %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := gerrors.WriteTypeScript(&buf, catalog.Mapper()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"export enum ErrorCode {",
+		"  UserNotFound = 100,",
+		`  | "user-not-found"`,
+		`  | "USER-NOT-FOUND"`,
+		"export interface UserNotFoundLabels {\n  \"user_id\": string;\n}",
+		`  "QUOTA": Record<string, string>;`,
+		`  "USER-NOT-FOUND": ErrorCode.UserNotFound,`,
+		"export function isAIP193Error(body: unknown): body is AIP193Error {",
+		"export function isProblemDetails(body: unknown): body is ProblemDetails {",
+		"export function parseError(body: unknown): GerrorsError | undefined {",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected module to contain %q:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestWriteTypeScriptAIP193(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := gerrors.ParseCatalog(strings.NewReader(testCatalog))
+	if err != nil {
+		t.Fatalf("unexpected error parsing catalog: %v", err)
+	}
+
+	f := gerrors.NewFormatter(gerrors.WithKeyCase(gerrors.KeyCaseLowerCamel),
+		gerrors.WithSystemKey(gerrors.MetadataErrorCode, "code"))
+
+	var buf bytes.Buffer
+
+	if err := gerrors.WriteTypeScript(&buf, catalog.Mapper(), gerrors.WithSchemaFormatter(f)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"export interface UserNotFoundLabels {\n  \"userId\": string;\n}",
+		`"_error_code", "errorCode"`,
+		`"code"`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected module to contain %q:\n%s", expected, buf.String())
+		}
+	}
+
+	strict, err := gerrors.NewFormatterE(gerrors.WithLookuper(catalog.Mapper()), gerrors.WithAIP193(),
+		gerrors.WithDomain("users.example.com"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	buf.Reset()
+
+	if err := gerrors.WriteTypeScript(&buf, catalog.Mapper(), gerrors.WithSchemaFormatter(strict)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `  | "USER_NOT_FOUND"`) || strings.Contains(buf.String(), `"USER-NOT-FOUND"`) {
+		t.Errorf("expected strict AIP-193 reasons:\n%s", buf.String())
+	}
+}
+
+func TestWriteTypeScriptNames(t *testing.T) {
+	t.Parallel()
+
+	catalog := &gerrors.Catalog{
+		UnknownCode: 1,
+		Entries: []*gerrors.CatalogEntry{
+			{Code: 1, Identifier: "unknown", Message: "closes */ comments"},
+			{Code: 2, Identifier: "404 page"},
+		},
+	}
+
+	if err := catalog.Validate(); err != nil {
+		t.Fatalf("unexpected error validating catalog: %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := gerrors.WriteTypeScript(&buf, catalog.Mapper()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Code404Page = 2,") {
+		t.Errorf("expected enum member names to be valid identifiers:\n%s", buf.String())
+	}
+
+	if strings.Contains(buf.String(), "closes */") {
+		t.Errorf("expected messages to be escaped in comments")
+	}
+}
+
+func TestWriteTypeScriptSharedReason(t *testing.T) {
+	t.Parallel()
+
+	catalog := &gerrors.Catalog{
+		UnknownCode: 1,
+		Entries: []*gerrors.CatalogEntry{
+			{Code: 1, Identifier: "unknown"},
+			{Code: 3, Identifier: "account-not-found", Reason: "NOT_FOUND"},
+			{Code: 2, Identifier: "user-not-found", Reason: "NOT_FOUND"},
+		},
+	}
+
+	if err := catalog.Validate(); err != nil {
+		t.Fatalf("unexpected error validating catalog: %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := gerrors.WriteTypeScript(&buf, catalog.Mapper()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for expected, count := range map[string]int{
+		`  | "NOT_FOUND"`:                           1,
+		`  "NOT_FOUND": Record<string, string>;`:    1,
+		`  "NOT_FOUND": ErrorCode.UserNotFound,`:    1,
+		`  "NOT_FOUND": "user-not-found",`:          1,
+		`  "NOT_FOUND": ErrorCode.AccountNotFound,`: 0,
+		"  AccountNotFound = 3,":                    1,
+	} {
+		if n := strings.Count(buf.String(), expected); n != count {
+			t.Errorf("expected module to contain %q %d times, got %d:\n%s", expected, count, n, buf.String())
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package gerrors
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ValueKind is the kind of a label value. Labels are kept typed internally
+// and they are only converted to string whenever a string representation is
+// required. e.g. in [GeneralError.Metadata] or in gRPC error details.
+// Check [WithValueEncoder] for customizing how each kind is converted to string.
+type ValueKind int
+
+const (
+	// KindAny is the kind of values that do not match any other kind.
+	// By default, they are encoded using fmt's %v verb.
+	KindAny ValueKind = iota
+
+	// KindNil is the kind of nil values, including nil pointers, maps and slices.
+	// By default, they are encoded as "null".
+	KindNil
+
+	// KindString is the kind of string values.
+	KindString
+
+	// KindBool is the kind of boolean values.
+	KindBool
+
+	// KindInt is the kind of signed integer values.
+	KindInt
+
+	// KindUint is the kind of unsigned integer values.
+	KindUint
+
+	// KindFloat is the kind of floating point values.
+	KindFloat
+
+	// KindDuration is the kind of [time.Duration] values.
+	// By default, they are encoded using [time.Duration.String]. e.g. 1m30s
+	KindDuration
+
+	// KindTime is the kind of [time.Time] values.
+	// By default, they are encoded using [time.RFC3339] layout.
+	KindTime
+
+	// KindBytes is the kind of byte slice values.
+	// By default, they are encoded as a hex string.
+	KindBytes
+
+	// KindError is the kind of values implementing error interface.
+	KindError
+
+	// KindStringer is the kind of values implementing [fmt.Stringer] interface.
+	KindStringer
+
+	// KindObject is the kind of structs, maps, slices and arrays.
+	// By default, they are encoded as JSON.
+	KindObject
+)
+
+// ValueEncoder converts a label value of a certain [ValueKind] to string.
+type ValueEncoder func(value any) string
+
+// labelValue is the typed representation of a label value.
+type labelValue struct {
+	value any
+	kind  ValueKind
+}
+
+// newLabelValue detects the kind of the value and returns its typed representation.
+// Non-nil pointers are dereferenced before detecting the kind.
+func newLabelValue(value any) labelValue {
+	if value == nil {
+		return labelValue{value: nil, kind: KindNil}
+	}
+
+	if lazy, ok := value.(lazyValue); ok {
+		return labelValue{value: lazy, kind: KindAny}
+	}
+
+	rv := reflect.ValueOf(value)
+
+	// nolint: exhaustive
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return labelValue{value: nil, kind: KindNil}
+		}
+	}
+
+	switch v := value.(type) {
+	case time.Duration:
+		return labelValue{value: v, kind: KindDuration}
+	case time.Time:
+		return labelValue{value: v, kind: KindTime}
+	case []byte:
+		return labelValue{value: v, kind: KindBytes}
+	case error:
+		return labelValue{value: v, kind: KindError}
+	case fmt.Stringer:
+		return labelValue{value: v, kind: KindStringer}
+	}
+
+	// nolint: exhaustive
+	switch rv.Kind() {
+	case reflect.Pointer:
+		return newLabelValue(rv.Elem().Interface())
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return labelValue{value: value, kind: KindObject}
+	case reflect.String:
+		return labelValue{value: value, kind: KindString}
+	case reflect.Bool:
+		return labelValue{value: value, kind: KindBool}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return labelValue{value: value, kind: KindInt}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return labelValue{value: value, kind: KindUint}
+	case reflect.Float32, reflect.Float64:
+		return labelValue{value: value, kind: KindFloat}
+	default:
+		return labelValue{value: value, kind: KindAny}
+	}
+}
+
+// stringLabelValue returns the typed representation of a string value.
+func stringLabelValue(value string) labelValue {
+	return labelValue{value: value, kind: KindString}
+}
+
+// resolve evaluates the value if it is lazy and returns its typed representation.
+// Non-lazy values are returned as is.
+func (v labelValue) resolve() labelValue {
+	lazy, ok := v.value.(lazyValue)
+	if !ok {
+		return v
+	}
+
+	if lazy == nil {
+		return labelValue{value: nil, kind: KindNil}
+	}
+
+	return newLabelValue(lazy()).resolve()
+}
+
+// getDefaultValueEncoders returns the encoders that are used by every formatter
+// unless they are customized using [WithValueEncoder].
+func getDefaultValueEncoders() map[ValueKind]ValueEncoder {
+	return map[ValueKind]ValueEncoder{
+		KindAny: encodeAny,
+		KindNil: func(any) string {
+			return "null"
+		},
+		KindString:   encodeAny,
+		KindBool:     encodeAny,
+		KindInt:      encodeAny,
+		KindUint:     encodeAny,
+		KindFloat:    encodeAny,
+		KindDuration: encodeAny,
+		KindTime: func(value any) string {
+			t, ok := value.(time.Time)
+			if !ok {
+				return encodeAny(value)
+			}
+
+			return t.Format(time.RFC3339)
+		},
+		KindBytes: func(value any) string {
+			b, ok := value.([]byte)
+			if !ok {
+				return encodeAny(value)
+			}
+
+			return hex.EncodeToString(b)
+		},
+		KindError: func(value any) string {
+			err, ok := value.(error)
+			if !ok {
+				return encodeAny(value)
+			}
+
+			return err.Error()
+		},
+		KindStringer: encodeAny,
+		KindObject: func(value any) string {
+			b, err := json.Marshal(value)
+			if err != nil {
+				return encodeAny(value)
+			}
+
+			return string(b)
+		},
+	}
+}
+
+func encodeAny(value any) string {
+	return fmt.Sprintf("%v", value)
+}
+
+// encodeValue converts the typed label value to string using the formatter's
+// encoder for the value's kind.
+func (f *Formatter) encodeValue(v labelValue) string {
+	v = v.resolve()
+
+	if encoder, ok := f.valueEncoders[v.kind]; ok && encoder != nil {
+		return encoder(v.value)
+	}
+
+	return encodeAny(v.value)
+}
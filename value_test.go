@@ -0,0 +1,115 @@
+package gerrors_test
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/seinshah/gerrors"
+)
+
+type sliceLogger struct {
+	keyValues []any
+}
+
+func TestValueEncoders(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nilPtr *int
+		num    = 42
+	)
+
+	ts := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{name: "string", value: "value", expected: "value"},
+		{name: "int", value: 10, expected: "10"},
+		{name: "float", value: 1.5, expected: "1.5"},
+		{name: "bool", value: true, expected: "true"},
+		{name: "nil", value: nil, expected: "null"},
+		{name: "nil pointer", value: nilPtr, expected: "null"},
+		{name: "pointer", value: &num, expected: "42"},
+		{name: "duration", value: 90 * time.Second, expected: "1m30s"},
+		{name: "time", value: ts, expected: "2024-05-01T10:30:00Z"},
+		{name: "bytes", value: []byte("hi"), expected: "6869"},
+		{name: "error", value: errors.New("failed"), expected: "failed"},
+		{name: "stringer", value: net.IPv4(127, 0, 0, 1), expected: "127.0.0.1"},
+		{name: "struct", value: struct{ A int }{A: 1}, expected: `{"A":1}`},
+		{name: "map", value: map[string]int{"a": 1}, expected: `{"a":1}`},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := gerrors.NewFormatter().New(nil, gerrors.Internal, "key", tc.value)
+
+			if err.Metadata()["key"] != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, err.Metadata()["key"])
+			}
+		})
+	}
+}
+
+func TestWithValueEncoder(t *testing.T) {
+	t.Parallel()
+
+	f := gerrors.NewFormatter(
+		gerrors.WithValueEncoder(gerrors.KindDuration, func(v any) string {
+			d, _ := v.(time.Duration)
+
+			return d.Round(time.Second).String()
+		}),
+		gerrors.WithLabels("timeout", 1500*time.Millisecond),
+	)
+
+	if f.LabelsMap()["timeout"] != "2s" {
+		t.Errorf("expected formatter label to use custom encoder, got %q", f.LabelsMap()["timeout"])
+	}
+
+	err := f.Clone().New(nil, gerrors.Internal, "elapsed", 2400*time.Millisecond)
+
+	if err.Metadata()["elapsed"] != "2s" {
+		t.Errorf("expected error label to use custom encoder, got %q", err.Metadata()["elapsed"])
+	}
+}
+
+func TestTypedValuesInLogger(t *testing.T) {
+	t.Parallel()
+
+	l := &sliceLogger{}
+	f := gerrors.NewFormatter(gerrors.WithLogger(l))
+
+	f.New(nil, gerrors.Internal, "elapsed", time.Second, "count", 3)
+
+	found := 0
+
+	for i := 0; i < len(l.keyValues); i += 2 {
+		switch l.keyValues[i] {
+		case "elapsed":
+			if _, ok := l.keyValues[i+1].(time.Duration); ok {
+				found++
+			}
+		case "count":
+			if _, ok := l.keyValues[i+1].(int); ok {
+				found++
+			}
+		}
+	}
+
+	if found != 2 {
+		t.Errorf("expected logger to receive typed values, got %v", l.keyValues)
+	}
+}
+
+func (l *sliceLogger) Error(_ error, _ string, keyValues ...any) {
+	l.keyValues = keyValues
+}
@@ -0,0 +1,75 @@
+package gerrors
+
+import "errors"
+
+// Wrap annotates err with the operation it is passing through and merges
+// the given key values into its labels. It is meant to be called while the
+// error bubbles up, so that the final error shows the path it took.
+// e.g. "handler > service > repo".
+// If err is already a [GeneralError], or wraps one, its code, labels and original
+// error are kept and the new labels override the existing ones with the same key.
+// The context added by the errors wrapping it is kept in the original error.
+// Check [Formatter.New] for more information.
+// Otherwise, err is converted to a [GeneralError] by [DefaultFormatter] using
+// [Unknown] code.
+// Wrap returns nil if err is nil. Wrapping does not log the error again.
+func Wrap(err error, operation string, keyValues ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	var inner *GeneralError
+	if !errors.As(err, &inner) {
+		return DefaultFormatter.createError(err, Unknown).wrap(operation, keyValues)
+	}
+
+	wrapped := inner.wrap(operation, keyValues)
+	wrapped.originalError = inner.contextError(err)
+
+	return wrapped
+}
+
+// Annotate is the deferrable version of [Wrap]. It wraps the error that errp
+// points to, if there is any, with the given operation and key values.
+//
+//	func (s *Service) GetUser(id string) (err error) {
+//		defer gerrors.Annotate(&err, "service.GetUser", "user_id", id)
+//		...
+//	}
+func Annotate(errp *error, operation string, keyValues ...any) {
+	if errp == nil || *errp == nil {
+		return
+	}
+
+	*errp = Wrap(*errp, operation, keyValues...)
+}
+
+func (ge *GeneralError) wrap(operation string, keyValues []any) *GeneralError {
+	err := &GeneralError{
+		originalError: ge.originalError,
+		coreError:     ge.coreError,
+		formatter:     ge.formatter,
+		requestedCode: ge.requestedCode,
+		fallback:      ge.fallback,
+		operations:    ge.operations,
+		stack:         ge.stack,
+		defaultLabels: ge.defaultLabels,
+		labels:        make(map[string]labelValue, len(ge.labels)),
+		metadata:      nil,
+		labelStrings:  nil,
+		message:       "",
+		details:       nil,
+	}
+
+	if operation != "" {
+		err.operations = append([]string{operation}, ge.operations...)
+	}
+
+	for k, v := range ge.labels {
+		err.labels[k] = v
+	}
+
+	err.addLabels(keyValues)
+
+	return err
+}
@@ -0,0 +1,188 @@
+package gerrors_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/seinshah/gerrors"
+)
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	if gerrors.Wrap(nil, "op") != nil {
+		t.Fatal("expected wrapping nil error to return nil")
+	}
+
+	f := gerrors.NewFormatter(gerrors.WithTemplate("{{.Operation}}: {{.Message}}"))
+	repoErr := f.New(errors.New("no rows"), gerrors.NotFound, "table", "users", "id", 1)
+
+	err := gerrors.Wrap(fmt.Errorf("repo: %w", repoErr), "repo", "id", 2)
+	err = gerrors.Wrap(err, "service", "user", "john")
+	err = gerrors.Wrap(err, "handler")
+
+	var ge *gerrors.GeneralError
+	if !errors.As(err, &ge) {
+		t.Fatalf("expected GeneralError, got %T", err)
+	}
+
+	if ge.Code() != gerrors.NotFound {
+		t.Errorf("expected code %d, got %d", gerrors.NotFound, ge.Code())
+	}
+
+	if ge.Error() != "handler > service > repo: repo: no rows" {
+		t.Errorf("unexpected error message: %s", ge.Error())
+	}
+
+	expected := map[string]string{
+		"table":                       "users",
+		"id":                          "2",
+		"user":                        "john",
+		gerrors.MetadataOperation:     "handler > service > repo",
+		gerrors.MetadataIdentifier:    "not-found",
+		gerrors.MetadataErrorCode:     "2",
+		gerrors.MetadataOriginalError: "repo: no rows",
+	}
+
+	for k, v := range expected {
+		if ge.Metadata()[k] != v {
+			t.Errorf("expected %s to be %q, got %q", k, v, ge.Metadata()[k])
+		}
+	}
+
+	if _, ok := repoErr.Metadata()[gerrors.MetadataOperation]; ok {
+		t.Errorf("wrapping should not modify the wrapped error")
+	}
+}
+
+func TestWrapNonGeneralError(t *testing.T) {
+	t.Parallel()
+
+	err := gerrors.Wrap(errors.New("plain"), "repo", "key", "value")
+
+	var ge *gerrors.GeneralError
+	if !errors.As(err, &ge) {
+		t.Fatalf("expected GeneralError, got %T", err)
+	}
+
+	if ge.Code() != gerrors.Unknown {
+		t.Errorf("expected code %d, got %d", gerrors.Unknown, ge.Code())
+	}
+
+	if ge.Operation() != "repo" || ge.Metadata()["key"] != "value" {
+		t.Errorf("unexpected operation or labels: %s %v", ge.Operation(), ge.Metadata())
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	t.Parallel()
+
+	fn := func(fail bool) (err error) {
+		defer gerrors.Annotate(&err, "fn", "fail", fail)
+
+		if fail {
+			return gerrors.DefaultFormatter.New(nil, gerrors.Storage)
+		}
+
+		return nil
+	}
+
+	if err := fn(false); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	var ge *gerrors.GeneralError
+	if !errors.As(fn(true), &ge) {
+		t.Fatal("expected GeneralError")
+	}
+
+	if ge.Operation() != "fn" || ge.Metadata()["fail"] != "true" || ge.Code() != gerrors.Storage {
+		t.Errorf("unexpected annotated error: %s %v", ge.Operation(), ge.Metadata())
+	}
+}
+
+func TestNewInheritsGeneralError(t *testing.T) {
+	t.Parallel()
+
+	inner := gerrors.Wrap(
+		gerrors.NewFormatter(gerrors.WithLabels("inner", "yes")).New(errors.New("root"), gerrors.Storage, "k", "v"),
+		"repo",
+	)
+
+	testCases := []struct {
+		name         string
+		code         gerrors.Code
+		expectedCode gerrors.Code
+	}{
+		{name: "inherited code", code: gerrors.InheritCode, expectedCode: gerrors.Storage},
+		{name: "explicit code", code: gerrors.Internal, expectedCode: gerrors.Internal},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := gerrors.NewFormatter(gerrors.WithLabels("outer", "yes")).New(inner, tc.code, "k", "v2")
+
+			if err.Code() != tc.expectedCode {
+				t.Errorf("expected code %d, got %d", tc.expectedCode, err.Code())
+			}
+
+			md := err.Metadata()
+			if md[gerrors.MetadataOriginalError] != "root" || md["inner"] != "yes" || md["outer"] != "yes" ||
+				md["k"] != "v2" || md[gerrors.MetadataOperation] != "repo" {
+				t.Errorf("unexpected metadata: %v", md)
+			}
+		})
+	}
+
+	if gerrors.DefaultFormatter.New(nil, gerrors.InheritCode).Code() != gerrors.Unknown {
+		t.Errorf("expected inherit code without an inner error to fall back to unknown")
+	}
+}
+
+func TestWrapKeepsContext(t *testing.T) {
+	t.Parallel()
+
+	f := gerrors.NewFormatter()
+
+	testCases := []struct {
+		name     string
+		inner    *gerrors.GeneralError
+		expected string
+	}{
+		{
+			name:     "original error",
+			inner:    f.New(errors.New("db down"), gerrors.Storage),
+			expected: "loading user 42: db down",
+		},
+		{
+			name:     "default message",
+			inner:    f.New(nil, gerrors.Storage),
+			expected: "loading user 42: " + f.New(nil, gerrors.Storage).DefaultMessage(),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			outer := fmt.Errorf("loading user 42: %w", tc.inner)
+
+			var wrapped *gerrors.GeneralError
+
+			errors.As(gerrors.Wrap(outer, "svc"), &wrapped)
+
+			for _, ge := range []*gerrors.GeneralError{wrapped, f.New(outer, gerrors.InheritCode)} {
+				if ge.Metadata()[gerrors.MetadataOriginalError] != tc.expected {
+					t.Errorf("expected original error %q, got %q", tc.expected, ge.Metadata()[gerrors.MetadataOriginalError])
+				}
+			}
+		})
+	}
+}